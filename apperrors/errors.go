@@ -0,0 +1,171 @@
+// Package apperrors defines the typed errors handlers return. The application's
+// fiber.Config.ErrorHandler renders them in the common response envelope, so
+// handlers never build error responses themselves.
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error codes returned in the code field of error responses
+const (
+	CodeBadRequest      = "bad_request"
+	CodeValidation      = "validation_failed"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeLocked          = "locked"
+	CodeTooManyRequests = "too_many_requests"
+	CodeInternal        = "internal_error"
+	CodeUnavailable     = "service_unavailable"
+)
+
+// FieldError describes a single invalid input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error is an error with the HTTP status, code and client-facing messages of its response.
+// The cause is logged but never sent to the client.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details []string
+	Fields  []FieldError
+	Headers map[string]string
+	cause   error
+}
+
+// New creates an error with the given status, code, message and details
+func New(status int, code, message string, details ...string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+		Details: details,
+	}
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// WithCause records the underlying error for logging
+func (e *Error) WithCause(err error) *Error {
+	e.cause = err
+	return e
+}
+
+// WithHeader adds a header to the error response, e.g. Retry-After
+func (e *Error) WithHeader(key, value string) *Error {
+	if e.Headers == nil {
+		e.Headers = make(map[string]string)
+	}
+	e.Headers[key] = value
+	return e
+}
+
+// BadRequest reports a request that could not be parsed
+func BadRequest(message string, details ...string) *Error {
+	return New(http.StatusBadRequest, CodeBadRequest, message, details...)
+}
+
+// Validation reports input that failed validation, one detail per problem
+func Validation(details ...string) *Error {
+	return New(http.StatusBadRequest, CodeValidation, "Validation failed", details...)
+}
+
+// InvalidFields reports input that failed field validation. The messages are
+// also listed as details for clients that only read those.
+func InvalidFields(fields []FieldError) *Error {
+	err := Validation()
+	err.Fields = fields
+	for _, field := range fields {
+		err.Details = append(err.Details, field.Message)
+	}
+	return err
+}
+
+// Unauthorized reports missing or invalid credentials
+func Unauthorized(detail string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, "Unauthorized", detail)
+}
+
+// Forbidden reports an authenticated user lacking permission
+func Forbidden(detail string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, "Forbidden", detail)
+}
+
+// NotFound reports a missing resource
+func NotFound(detail string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, "Not found", detail)
+}
+
+// Conflict reports a request that clashes with existing data, e.g. a duplicate email
+func Conflict(detail string) *Error {
+	return New(http.StatusConflict, CodeConflict, "Conflict", detail)
+}
+
+// Locked reports a resource that is temporarily locked
+func Locked(detail string) *Error {
+	return New(http.StatusLocked, CodeLocked, "Locked", detail)
+}
+
+// TooManyRequests reports a client exceeding a rate limit
+func TooManyRequests(detail string) *Error {
+	return New(http.StatusTooManyRequests, CodeTooManyRequests, "Too many requests", detail)
+}
+
+// Internal reports an unexpected failure; detail is shown to the client and cause is only logged
+func Internal(detail string, cause error) *Error {
+	return New(http.StatusInternalServerError, CodeInternal, "Server error", detail).WithCause(cause)
+}
+
+// As returns the *Error in err's chain, if any
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// CodeForStatus returns the error code used for responses with the given status
+func CodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusLocked:
+		return CodeLocked
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
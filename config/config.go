@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Config holds every setting of the application. Each field is read from the
+// environment variable named by its env tag, falling back to the optional YAML
+// file and then to the value of its default tag.
+type Config struct {
+	App       AppConfig       `yaml:"app"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Log       LogConfig       `yaml:"log"`
+	Audit     AuditConfig     `yaml:"audit"`
+	AsyncLog  AsyncLogConfig  `yaml:"async_log"`
+	Retention RetentionConfig `yaml:"retention"`
+	Redact    RedactConfig    `yaml:"redact"`
+	Health    HealthConfig    `yaml:"health"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+// AppConfig holds the HTTP server settings
+type AppConfig struct {
+	Name            string        `yaml:"name" env:"APP_NAME" default:"go-fiber-template"`
+	Env             string        `yaml:"env" env:"APP_ENV" default:"local"`
+	Debug           bool          `yaml:"debug" env:"APP_DEBUG" default:"true"`
+	Host            string        `yaml:"host" env:"APP_HOST" default:"localhost"`
+	Port            int           `yaml:"port" env:"APP_PORT" default:"8001"`
+	FrontendURL     string        `yaml:"frontend_url" env:"FRONTEND_URL" default:"http://localhost:3000"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	// ShutdownDrainDelay keeps serving while /readyz reports not ready, before connections are closed
+	ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY" default:"0s"`
+	// PhoneDefaultCountryCode is prepended to phone numbers entered without a country code
+	PhoneDefaultCountryCode string `yaml:"phone_default_country_code" env:"PHONE_DEFAULT_COUNTRY_CODE"`
+}
+
+// DatabaseConfig holds the PostgreSQL connection settings
+type DatabaseConfig struct {
+	Host               string        `yaml:"host" env:"DB_HOST" default:"localhost"`
+	Port               int           `yaml:"port" env:"DB_PORT" default:"5432"`
+	Name               string        `yaml:"name" env:"DB_NAME"`
+	User               string        `yaml:"user" env:"DB_USER"`
+	Password           string        `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	SSLMode            string        `yaml:"sslmode" env:"DB_SSLMODE" default:"disable"`
+	LogLevel           string        `yaml:"log_level" env:"DB_LOG_LEVEL" default:"warn"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD" default:"200ms"`
+	// AutoMigrate migrates the schema on startup, otherwise it is left to the migrate command
+	AutoMigrate bool `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE" default:"false"`
+}
+
+// AuthConfig holds the token settings
+type AuthConfig struct {
+	JWTSecret       string        `yaml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl" env:"JWT_ACCESS_TTL" default:"15m"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env:"JWT_REFRESH_TTL" default:"720h"`
+	InvitationTTL   time.Duration `yaml:"invitation_ttl" env:"INVITATION_TTL" default:"72h"`
+}
+
+// LogConfig holds the logging backend settings
+type LogConfig struct {
+	Format string `yaml:"format" env:"LOG_FORMAT" default:"text"`
+	// Level defaults to debug, or info when APP_ENV is production
+	Level         string `yaml:"level" env:"LOG_LEVEL"`
+	PackageLevels string `yaml:"package_levels" env:"LOG_PACKAGE_LEVELS"`
+}
+
+// AuditConfig holds the request audit settings
+type AuditConfig struct {
+	IncludePaths []string `yaml:"include" env:"AUDIT_LOG_INCLUDE"`
+	ExcludePaths []string `yaml:"exclude" env:"AUDIT_LOG_EXCLUDE" default:"/healthz,/readyz,/metrics"`
+	MaxBodySize  int      `yaml:"max_body" env:"AUDIT_LOG_MAX_BODY" default:"65536"`
+}
+
+// AsyncLogConfig holds the buffering settings of the asynchronous database logger
+type AsyncLogConfig struct {
+	BufferSize     int           `yaml:"buffer_size" env:"ASYNC_LOG_BUFFER_SIZE" default:"1000"`
+	BatchSize      int           `yaml:"batch_size" env:"ASYNC_LOG_BATCH_SIZE" default:"100"`
+	FlushInterval  time.Duration `yaml:"flush_interval" env:"ASYNC_LOG_FLUSH_INTERVAL" default:"2s"`
+	OverflowPolicy string        `yaml:"overflow" env:"ASYNC_LOG_OVERFLOW" default:"drop_newest"`
+}
+
+// RetentionConfig holds the log retention and archival settings
+type RetentionConfig struct {
+	DBRetentionDays   int           `yaml:"db_retention_days" env:"LOG_DB_RETENTION_DAYS" default:"90"`
+	BatchSize         int           `yaml:"batch_size" env:"LOG_RETENTION_BATCH_SIZE" default:"1000"`
+	ArchiveEnabled    bool          `yaml:"archive_enabled" env:"LOG_ARCHIVE_ENABLED" default:"true"`
+	ArchiveDir        string        `yaml:"archive_dir" env:"LOG_ARCHIVE_DIR" default:"logs/archive"`
+	CompressFiles     bool          `yaml:"compress_files" env:"LOG_FILE_COMPRESS" default:"true"`
+	FileRetentionDays int           `yaml:"file_retention_days" env:"LOG_FILE_RETENTION_DAYS" default:"30"`
+	Interval          time.Duration `yaml:"interval" env:"LOG_RETENTION_INTERVAL" default:"1h"`
+}
+
+// RedactConfig holds redaction rules added to the built-in defaults
+type RedactConfig struct {
+	JSONPaths []string `yaml:"json_paths" env:"REDACT_JSON_PATHS"`
+	Headers   []string `yaml:"headers" env:"REDACT_HEADERS"`
+}
+
+// HealthConfig holds the readiness check thresholds
+type HealthConfig struct {
+	CheckTimeout     time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+	MinFreeDiskMB    int           `yaml:"min_free_disk_mb" env:"HEALTH_MIN_FREE_DISK_MB" default:"100"`
+	MaxLogQueueRatio float64       `yaml:"max_log_queue_ratio" env:"HEALTH_MAX_LOG_QUEUE_RATIO" default:"0.9"`
+}
+
+// RateLimitConfig holds the auth endpoint rate limits and the account lockout policy
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED" default:"true"`
+	// Store is memory for a single instance or postgres to share counters between instances
+	Store     string `yaml:"store" env:"RATE_LIMIT_STORE" default:"memory"`
+	Algorithm string `yaml:"algorithm" env:"RATE_LIMIT_ALGORITHM" default:"sliding"`
+	// AuthIPLimit applies to every /api/auth endpoint per client IP
+	AuthIPLimit  int           `yaml:"auth_ip_limit" env:"RATE_LIMIT_AUTH_IP_LIMIT" default:"60"`
+	AuthIPWindow time.Duration `yaml:"auth_ip_window" env:"RATE_LIMIT_AUTH_IP_WINDOW" default:"1m"`
+	// LoginIPLimit and LoginAccountLimit apply to login attempts per client IP and per email
+	LoginIPLimit       int           `yaml:"login_ip_limit" env:"RATE_LIMIT_LOGIN_IP_LIMIT" default:"20"`
+	LoginIPWindow      time.Duration `yaml:"login_ip_window" env:"RATE_LIMIT_LOGIN_IP_WINDOW" default:"5m"`
+	LoginAccountLimit  int           `yaml:"login_account_limit" env:"RATE_LIMIT_LOGIN_ACCOUNT_LIMIT" default:"10"`
+	LoginAccountWindow time.Duration `yaml:"login_account_window" env:"RATE_LIMIT_LOGIN_ACCOUNT_WINDOW" default:"15m"`
+	// LockoutThreshold failed passwords lock the account, 0 disables lockout.
+	// Each further LockoutThreshold failures doubles the duration up to LockoutMaxDuration.
+	LockoutThreshold   int           `yaml:"lockout_threshold" env:"LOGIN_LOCKOUT_THRESHOLD" default:"5"`
+	LockoutDuration    time.Duration `yaml:"lockout_duration" env:"LOGIN_LOCKOUT_DURATION" default:"15m"`
+	LockoutMaxDuration time.Duration `yaml:"lockout_max_duration" env:"LOGIN_LOCKOUT_MAX_DURATION" default:"24h"`
+}
+
+// IsProduction reports whether the application runs in production
+func (c AppConfig) IsProduction() bool {
+	return c.Env == "production"
+}
+
+// Address returns the address the HTTP server listens on
+func (c AppConfig) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// DSN returns the PostgreSQL connection string
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
+}
+
+// URL returns a postgres:// URL for the database, e.g. for migration tools
+func (c DatabaseConfig) URL() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:     c.Name,
+		RawQuery: "sslmode=" + url.QueryEscape(c.SSLMode),
+	}
+	return u.String()
+}
+
+var (
+	currentMu sync.RWMutex
+	current   = Default()
+)
+
+// Set replaces the configuration returned by Get
+func Set(cfg *Config) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = cfg
+}
+
+// Get returns the loaded application configuration, or the defaults before Set is called
+func Get() *Config {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultConfigFile = "config.yaml"
+	minJWTSecretLen   = 32
+	maskedValue       = "********"
+)
+
+var (
+	durationType       = reflect.TypeOf(time.Duration(0))
+	countryCodePattern = regexp.MustCompile(`^[1-9][0-9]{0,2}$`)
+)
+
+// ValidationError lists every invalid configuration key found while loading
+type ValidationError struct {
+	Problems []string
+}
+
+// Error implements error
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Default returns the configuration with every default value applied
+func Default() *Config {
+	cfg := &Config{}
+	walkFields(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, info reflect.StructField) {
+		if value, ok := info.Tag.Lookup("default"); ok {
+			// Defaults are constants checked by Validate, so the error is ignored
+			_ = setField(field, value)
+		}
+	})
+	return cfg
+}
+
+// Load builds the configuration from the defaults, the optional YAML file and
+// the environment, in increasing order of precedence. The .env file is loaded
+// into the environment first without overriding variables that are already set.
+// The YAML file is read from CONFIG_FILE, or config.yaml when it exists.
+// Every invalid key is reported at once in a *ValidationError.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	cfg := Default()
+	var problems []string
+
+	path, explicit := os.LookupEnv("CONFIG_FILE")
+	if !explicit {
+		path = defaultConfigFile
+	}
+	if err := loadYAML(cfg, path); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			problems = append(problems, fmt.Sprintf("CONFIG_FILE: %v", err))
+		}
+	}
+
+	problems = append(problems, loadEnv(cfg)...)
+
+	if cfg.Log.Level == "" {
+		cfg.Log.Level = "debug"
+		if cfg.App.IsProduction() {
+			cfg.Log.Level = "info"
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			problems = append(problems, validationErr.Problems...)
+		} else {
+			return nil, err
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// loadYAML decodes the YAML file at path into cfg, rejecting unknown keys
+func loadYAML(cfg *Config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overrides cfg with every non-empty environment variable named by an env tag
+func loadEnv(cfg *Config) []string {
+	var problems []string
+	walkFields(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, info reflect.StructField) {
+		key := info.Tag.Get("env")
+		if key == "" {
+			return
+		}
+		value := strings.TrimSpace(os.Getenv(key))
+		if value == "" {
+			return
+		}
+		if err := setField(field, value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+		}
+	})
+	return problems
+}
+
+// walkFields calls fn for every leaf field of the nested config structs
+func walkFields(v reflect.Value, fn func(field reflect.Value, info reflect.StructField)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		info := v.Type().Field(i)
+		if field.Kind() == reflect.Struct {
+			walkFields(field, fn)
+			continue
+		}
+		fn(field, info)
+	}
+}
+
+// setField parses value into the field according to its type
+func setField(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// Validate checks every setting and reports all invalid keys in a *ValidationError
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.App.Port > 0 && c.App.Port <= 65535, "APP_PORT", "must be between 1 and 65535")
+	check(c.App.FrontendURL != "", "FRONTEND_URL", "is required")
+	check(c.App.PhoneDefaultCountryCode == "" || countryCodePattern.MatchString(c.App.PhoneDefaultCountryCode),
+		"PHONE_DEFAULT_COUNTRY_CODE", "must be 1 to 3 digits without a leading zero or +")
+	check(c.App.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT", "must be positive")
+	check(c.App.ShutdownDrainDelay >= 0 && c.App.ShutdownDrainDelay < c.App.ShutdownTimeout,
+		"SHUTDOWN_DRAIN_DELAY", "must not be negative and must be shorter than SHUTDOWN_TIMEOUT")
+
+	check(c.Database.Host != "", "DB_HOST", "is required")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "DB_PORT", "must be between 1 and 65535")
+	check(c.Database.Name != "", "DB_NAME", "is required")
+	check(c.Database.User != "", "DB_USER", "is required")
+	check(oneOf(c.Database.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+		"DB_SSLMODE", "must be one of disable, allow, prefer, require, verify-ca, verify-full")
+	check(oneOf(c.Database.LogLevel, "silent", "error", "warn", "info"),
+		"DB_LOG_LEVEL", "must be one of silent, error, warn, info")
+	check(c.Database.SlowQueryThreshold >= 0, "DB_SLOW_QUERY_THRESHOLD", "must not be negative")
+
+	check(len(c.Auth.JWTSecret) >= minJWTSecretLen, "JWT_SECRET", "must be at least %d characters long", minJWTSecretLen)
+	check(c.Auth.AccessTokenTTL > 0, "JWT_ACCESS_TTL", "must be positive")
+	check(c.Auth.RefreshTokenTTL > 0, "JWT_REFRESH_TTL", "must be positive")
+	check(c.Auth.InvitationTTL > 0, "INVITATION_TTL", "must be positive")
+
+	check(oneOf(c.Log.Format, "text", "json"), "LOG_FORMAT", "must be text or json")
+	check(c.Log.Level == "" || isLogLevel(c.Log.Level), "LOG_LEVEL", "must be one of debug, info, warn, error")
+	if err := validatePackageLevels(c.Log.PackageLevels); err != nil {
+		check(false, "LOG_PACKAGE_LEVELS", "%v", err)
+	}
+
+	check(c.Audit.MaxBodySize >= 0, "AUDIT_LOG_MAX_BODY", "must not be negative")
+
+	check(c.AsyncLog.BufferSize > 0, "ASYNC_LOG_BUFFER_SIZE", "must be positive")
+	check(c.AsyncLog.BatchSize > 0, "ASYNC_LOG_BATCH_SIZE", "must be positive")
+	check(c.AsyncLog.FlushInterval > 0, "ASYNC_LOG_FLUSH_INTERVAL", "must be positive")
+	check(oneOf(c.AsyncLog.OverflowPolicy, "block", "drop_oldest", "drop_newest"),
+		"ASYNC_LOG_OVERFLOW", "must be one of block, drop_oldest, drop_newest")
+
+	check(c.Retention.DBRetentionDays >= 0, "LOG_DB_RETENTION_DAYS", "must not be negative")
+	check(c.Retention.FileRetentionDays >= 0, "LOG_FILE_RETENTION_DAYS", "must not be negative")
+	check(c.Retention.BatchSize > 0, "LOG_RETENTION_BATCH_SIZE", "must be positive")
+	check(c.Retention.ArchiveDir != "", "LOG_ARCHIVE_DIR", "is required")
+	check(c.Retention.Interval > 0, "LOG_RETENTION_INTERVAL", "must be positive")
+
+	check(c.Health.CheckTimeout > 0, "HEALTH_CHECK_TIMEOUT", "must be positive")
+	check(c.Health.MinFreeDiskMB >= 0, "HEALTH_MIN_FREE_DISK_MB", "must not be negative")
+	check(c.Health.MaxLogQueueRatio > 0 && c.Health.MaxLogQueueRatio <= 1, "HEALTH_MAX_LOG_QUEUE_RATIO", "must be greater than 0 and at most 1")
+
+	check(oneOf(c.RateLimit.Store, "memory", "postgres"), "RATE_LIMIT_STORE", "must be memory or postgres")
+	check(oneOf(c.RateLimit.Algorithm, "fixed", "sliding"), "RATE_LIMIT_ALGORITHM", "must be fixed or sliding")
+	check(c.RateLimit.AuthIPLimit > 0, "RATE_LIMIT_AUTH_IP_LIMIT", "must be positive")
+	check(c.RateLimit.AuthIPWindow > 0, "RATE_LIMIT_AUTH_IP_WINDOW", "must be positive")
+	check(c.RateLimit.LoginIPLimit > 0, "RATE_LIMIT_LOGIN_IP_LIMIT", "must be positive")
+	check(c.RateLimit.LoginIPWindow > 0, "RATE_LIMIT_LOGIN_IP_WINDOW", "must be positive")
+	check(c.RateLimit.LoginAccountLimit > 0, "RATE_LIMIT_LOGIN_ACCOUNT_LIMIT", "must be positive")
+	check(c.RateLimit.LoginAccountWindow > 0, "RATE_LIMIT_LOGIN_ACCOUNT_WINDOW", "must be positive")
+	check(c.RateLimit.LockoutThreshold >= 0, "LOGIN_LOCKOUT_THRESHOLD", "must not be negative")
+	check(c.RateLimit.LockoutDuration > 0, "LOGIN_LOCKOUT_DURATION", "must be positive")
+	check(c.RateLimit.LockoutMaxDuration >= c.RateLimit.LockoutDuration,
+		"LOGIN_LOCKOUT_MAX_DURATION", "must not be shorter than LOGIN_LOCKOUT_DURATION")
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// String lists every setting by environment variable name with secrets masked
+func (c *Config) String() string {
+	var b strings.Builder
+	walkFields(reflect.ValueOf(c).Elem(), func(field reflect.Value, info reflect.StructField) {
+		value := fmt.Sprint(field.Interface())
+		if field.Kind() == reflect.Slice {
+			value = strings.Join(field.Interface().([]string), ",")
+		}
+		if info.Tag.Get("secret") == "true" && value != "" {
+			value = maskedValue
+		}
+		fmt.Fprintf(&b, "%s=%s\n", info.Tag.Get("env"), value)
+	})
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// oneOf reports whether value is one of the allowed values
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// isLogLevel reports whether value names a log level
+func isLogLevel(value string) bool {
+	return oneOf(strings.ToLower(value), "debug", "info", "warn", "warning", "error")
+}
+
+// validatePackageLevels checks a "package=level,..." list
+func validatePackageLevels(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid entry %q, expected package=level", pair)
+		}
+		if !isLogLevel(strings.TrimSpace(level)) {
+			return fmt.Errorf("invalid level %q for package %s", level, name)
+		}
+	}
+	return nil
+}
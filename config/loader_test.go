@@ -0,0 +1,213 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// requiredEnv holds valid values for every setting without a default
+var requiredEnv = map[string]string{
+	"DB_NAME":    "app",
+	"DB_USER":    "app",
+	"JWT_SECRET": strings.Repeat("s", minJWTSecretLen),
+}
+
+// setupEnv sets the required settings plus env, and points CONFIG_FILE at yaml
+// when it is not empty
+func setupEnv(t *testing.T, env map[string]string, yaml string) {
+	t.Helper()
+	for key, value := range requiredEnv {
+		t.Setenv(key, value)
+	}
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+	if yaml != "" {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("CONFIG_FILE", path)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		yaml  string
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.App.Port != 8001 || cfg.Auth.AccessTokenTTL != 15*time.Minute || cfg.Database.AutoMigrate {
+					t.Errorf("got port %d, access TTL %s, auto migrate %v", cfg.App.Port, cfg.Auth.AccessTokenTTL, cfg.Database.AutoMigrate)
+				}
+				if !slices.Equal(cfg.Audit.ExcludePaths, []string{"/healthz", "/readyz", "/metrics"}) {
+					t.Errorf("audit exclude = %v", cfg.Audit.ExcludePaths)
+				}
+			},
+		},
+		{
+			name: "YAML overrides defaults",
+			yaml: "app:\n  port: 9000\ndatabase:\n  auto_migrate: true\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.App.Port != 9000 || !cfg.Database.AutoMigrate {
+					t.Errorf("got port %d, auto migrate %v", cfg.App.Port, cfg.Database.AutoMigrate)
+				}
+			},
+		},
+		{
+			name: "environment overrides YAML",
+			env:  map[string]string{"APP_PORT": " 9100 ", "AUDIT_LOG_EXCLUDE": "/a, ,/b"},
+			yaml: "app:\n  port: 9000\n  name: from-yaml\naudit:\n  exclude: [/c]\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.App.Port != 9100 || cfg.App.Name != "from-yaml" {
+					t.Errorf("got port %d, name %q", cfg.App.Port, cfg.App.Name)
+				}
+				if !slices.Equal(cfg.Audit.ExcludePaths, []string{"/a", "/b"}) {
+					t.Errorf("audit exclude = %v", cfg.Audit.ExcludePaths)
+				}
+			},
+		},
+		{
+			name: "empty variables are ignored",
+			env:  map[string]string{"APP_PORT": ""},
+			yaml: "app:\n  port: 9000\n",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.App.Port != 9000 {
+					t.Errorf("got port %d, want 9000", cfg.App.Port)
+				}
+			},
+		},
+		{
+			name: "log level defaults to debug",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Log.Level != "debug" {
+					t.Errorf("log level = %q, want debug", cfg.Log.Level)
+				}
+			},
+		},
+		{
+			name: "log level defaults to info in production",
+			env:  map[string]string{"APP_ENV": "production"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Log.Level != "info" {
+					t.Errorf("log level = %q, want info", cfg.Log.Level)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupEnv(t, tt.env, tt.yaml)
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		yaml         string
+		wantProblems []string
+	}{
+		{
+			name:         "missing required settings are all reported",
+			env:          map[string]string{"DB_NAME": "", "JWT_SECRET": ""},
+			wantProblems: []string{"DB_NAME: is required", "JWT_SECRET: must be at least 32 characters long"},
+		},
+		{
+			name:         "unparsable values",
+			env:          map[string]string{"APP_PORT": "http", "JWT_ACCESS_TTL": "15", "APP_DEBUG": "maybe"},
+			wantProblems: []string{`APP_PORT: invalid integer "http"`, `JWT_ACCESS_TTL: invalid duration "15"`, `APP_DEBUG: invalid boolean "maybe"`},
+		},
+		{
+			name:         "out of range values",
+			env:          map[string]string{"APP_PORT": "70000", "LOG_FORMAT": "xml", "HEALTH_MAX_LOG_QUEUE_RATIO": "1.5"},
+			wantProblems: []string{"APP_PORT: must be between 1 and 65535", "LOG_FORMAT: must be text or json", "HEALTH_MAX_LOG_QUEUE_RATIO: must be greater than 0 and at most 1"},
+		},
+		{
+			name:         "dependent values",
+			env:          map[string]string{"SHUTDOWN_DRAIN_DELAY": "30s", "LOGIN_LOCKOUT_MAX_DURATION": "1m"},
+			wantProblems: []string{"SHUTDOWN_DRAIN_DELAY: must not be negative", "LOGIN_LOCKOUT_MAX_DURATION: must not be shorter"},
+		},
+		{
+			name:         "package levels",
+			env:          map[string]string{"LOG_PACKAGE_LEVELS": "database=debug,helpers"},
+			wantProblems: []string{`LOG_PACKAGE_LEVELS: invalid entry "helpers"`},
+		},
+		{
+			name:         "country code",
+			env:          map[string]string{"PHONE_DEFAULT_COUNTRY_CODE": "+44"},
+			wantProblems: []string{"PHONE_DEFAULT_COUNTRY_CODE: must be 1 to 3 digits"},
+		},
+		{
+			name:         "unknown YAML key",
+			yaml:         "app:\n  prot: 9000\n",
+			wantProblems: []string{"CONFIG_FILE:", "field prot not found"},
+		},
+		{
+			name:         "missing explicit config file",
+			env:          map[string]string{"CONFIG_FILE": "missing.yaml"},
+			wantProblems: []string{"CONFIG_FILE: open missing.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupEnv(t, tt.env, tt.yaml)
+			_, err := Load()
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Load() error = %v, want a *ValidationError", err)
+			}
+			for _, want := range tt.wantProblems {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error does not contain %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "hunter2"
+	cfg.Audit.IncludePaths = []string{"/api/*", "/auth/*"}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "secrets are masked", want: "DB_PASSWORD=" + maskedValue + "\n"},
+		{name: "empty secrets stay empty", want: "JWT_SECRET=\n"},
+		{name: "lists are comma-separated", want: "AUDIT_LOG_INCLUDE=/api/*,/auth/*\n"},
+		{name: "durations", want: "JWT_ACCESS_TTL=15m0s\n"},
+	}
+
+	output := cfg.String() + "\n"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("String() does not contain %q:\n%s", tt.want, output)
+			}
+		})
+	}
+	if strings.Contains(output, "hunter2") {
+		t.Error("String() leaks the database password")
+	}
+}
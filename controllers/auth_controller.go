@@ -1,93 +1,339 @@
 package controllers
 
 import (
+	"errors"
+	"go-fiber-template/apperrors"
+	"time"
+
 	"go-fiber-template/helpers"
 	"go-fiber-template/models"
 	"go-fiber-template/requests"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// errRefreshTokenReused is returned when a refresh token was rotated concurrently
+var errRefreshTokenReused = errors.New("refresh token already rotated")
+
 type AuthController struct {
 	DB *gorm.DB
+	// AccountLimiter throttles login attempts per email; nil disables it
+	AccountLimiter *helpers.RateLimiter
 }
 
 func NewAuthController(db *gorm.DB) *AuthController {
-	return &AuthController{DB: db}
+	return &AuthController{DB: db, AccountLimiter: helpers.LoginAccountLimiter()}
 }
 
 func (ac *AuthController) Login(c *fiber.Ctx) error {
 	input := new(requests.LoginRequest)
 	if err := c.BodyParser(input); err != nil {
-		helpers.Error("Failed to parse login input: %v", err)
-		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid input", nil)
+		return apperrors.BadRequest("Invalid input").WithCause(err)
+	}
+
+	if err := validateInput(c, input); err != nil {
+		return err
+	}
+
+	// Users log in with their email, or with their phone number when no email is given
+	column, identifier := "email", requests.NormalizeEmail(input.Email)
+	if identifier == "" {
+		phone, err := requests.NormalizePhone(input.Phone)
+		if err != nil {
+			return apperrors.Unauthorized("Invalid credentials")
+		}
+		column, identifier = "phone", phone
 	}
 
-	if errors := input.Validate(); len(errors) > 0 {
-		return helpers.ValidationErrorResponse(c, errors)
+	if ac.AccountLimiter != nil {
+		result, err := ac.AccountLimiter.Allow(c.UserContext(), helpers.LoginAccountKey(identifier))
+		if err != nil {
+			helpers.WithRequest(c).Warning("Login account rate limiter unavailable: %v", err)
+		} else if !result.Allowed {
+			helpers.LoginAttemptsTotal.Inc("rate_limited")
+			helpers.RateLimitedRequestsTotal.Inc(ac.AccountLimiter.Name())
+			helpers.SetRateLimitHeaders(c, result)
+			return helpers.RateLimitError(result.RetryAfter)
+		}
 	}
 
 	var user models.User
-	if err := ac.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		return helpers.UnauthorizedResponse(c)
+	if err := requestDB(ac.DB, c).Where(column+" = ?", identifier).First(&user).Error; err != nil {
+		helpers.LoginAttemptsTotal.Inc("failure")
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	// A locked account answers a wrong password like an unknown account, so the lockout
+	// only shows to someone who knows the password. Failures while locked are not counted.
+	if now := time.Now(); user.IsLocked(now) {
+		helpers.LoginAttemptsTotal.Inc("locked")
+		if err := user.ComparePassword(input.Password); err != nil {
+			return apperrors.Unauthorized("Invalid credentials")
+		}
+		return apperrors.Locked("Account is temporarily locked after too many failed login attempts").
+			WithHeader(fiber.HeaderRetryAfter, helpers.RetryAfterSeconds(user.LockedUntil.Sub(now)))
 	}
 
 	if err := user.ComparePassword(input.Password); err != nil {
-		return helpers.UnauthorizedResponse(c)
+		helpers.LoginAttemptsTotal.Inc("failure")
+		ac.recordFailedLogin(c, &user)
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := clearLoginFailures(requestDB(ac.DB, c), &user); err != nil {
+			helpers.WithRequest(c).Error("Failed to reset failed login attempts", err)
+		}
+	}
+
+	if !user.IsActive {
+		helpers.LoginAttemptsTotal.Inc("failure")
+		return apperrors.Forbidden("Account is deactivated")
+	}
+
+	familyID, err := helpers.GenerateTokenFamily()
+	if err != nil {
+		return apperrors.Internal("Could not generate token", err)
+	}
+
+	tokens, err := ac.issueTokens(requestDB(ac.DB, c), &user, familyID, nil)
+	if err != nil {
+		return apperrors.Internal("Could not generate token", err)
 	}
+	tokens["user"] = user
+	helpers.LoginAttemptsTotal.Inc("success")
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Login successful", tokens)
+}
 
-	tokenString, err := helpers.GenerateJWTToken(user.ID, user.Email)
+// recordFailedLogin counts a wrong password and locks the account once the
+// lockout threshold is reached
+func (ac *AuthController) recordFailedLogin(c *fiber.Ctx, user *models.User) {
+	err := requestDB(ac.DB, c).Model(user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+		UpdateColumn("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error
 	if err != nil {
-		return helpers.ServerErrorResponse(c, "Could not generate token")
+		helpers.WithRequest(c).Error("Failed to record failed login attempt", err)
+		return
 	}
 
-	return helpers.SuccessResponse(c, fiber.StatusOK, "Login successful", fiber.Map{
-		"token": tokenString,
-		"user":  user,
+	duration := helpers.LoginLockoutDuration(user.FailedLoginAttempts)
+	if duration <= 0 {
+		return
+	}
+	if err := requestDB(ac.DB, c).Model(user).UpdateColumn("locked_until", time.Now().Add(duration)).Error; err != nil {
+		helpers.WithRequest(c).Error("Failed to lock account", err)
+		return
+	}
+	helpers.WithRequest(c).Warning("Account %d locked for %s after %d failed login attempts",
+		user.ID, duration, user.FailedLoginAttempts)
+}
+
+// clearLoginFailures resets the failed login counter and lifts any lockout
+func clearLoginFailures(db *gorm.DB, user *models.User) error {
+	err := db.Model(user).UpdateColumns(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error
+	if err != nil {
+		return err
+	}
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+	return nil
+}
+
+// Refresh rotates a refresh token and issues a new access token.
+// Presenting a token that was already rotated revokes its whole family.
+func (ac *AuthController) Refresh(c *fiber.Ctx) error {
+	input := new(requests.RefreshRequest)
+	if err := c.BodyParser(input); err != nil {
+		return apperrors.BadRequest("Invalid input").WithCause(err)
+	}
+
+	if err := validateInput(c, input); err != nil {
+		return err
+	}
+
+	var current models.RefreshToken
+	if err := requestDB(ac.DB, c).Where("token_hash = ?", helpers.HashToken(input.RefreshToken)).First(&current).Error; err != nil {
+		return errInvalidRefreshToken()
+	}
+
+	if current.IsRevoked() {
+		ac.revokeTokenFamily(c, current.FamilyID)
+		helpers.WithRequest(c).Warning("Refresh token reuse detected for user %d, family %s revoked", current.UserID, current.FamilyID)
+		return errInvalidRefreshToken()
+	}
+
+	if current.IsExpired() {
+		return errInvalidRefreshToken()
+	}
+
+	var user models.User
+	if err := requestDB(ac.DB, c).First(&user, current.UserID).Error; err != nil || !user.IsActive {
+		return errInvalidRefreshToken()
+	}
+
+	var tokens fiber.Map
+	reused := false
+	err := requestDB(ac.DB, c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		tokens, err = ac.issueTokens(tx, &user, current.FamilyID, &current)
+		if err == errRefreshTokenReused {
+			reused = true
+		}
+		return err
 	})
+
+	if reused {
+		ac.revokeTokenFamily(c, current.FamilyID)
+		helpers.WithRequest(c).Warning("Refresh token reuse detected for user %d, family %s revoked", current.UserID, current.FamilyID)
+		return errInvalidRefreshToken()
+	}
+	if err != nil {
+		return apperrors.Internal("Could not generate token", err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Token refreshed successfully", tokens)
+}
+
+// Logout revokes the current access token and, when supplied, the refresh token family
+func (ac *AuthController) Logout(c *fiber.Ctx) error {
+	claims := helpers.GetAuthUser(c)
+	if claims == nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	input := new(requests.LogoutRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(input); err != nil {
+			return apperrors.BadRequest("Invalid input").WithCause(err)
+		}
+	}
+
+	if err := helpers.GetRevocationStore().RevokeToken(c.UserContext(), claims.ID, claims.UserID, claims.ExpiresAtTime()); err != nil {
+		return apperrors.Internal("Could not log out", err)
+	}
+
+	if input.RefreshToken != "" {
+		var refreshToken models.RefreshToken
+		err := requestDB(ac.DB, c).Where("token_hash = ? AND user_id = ?", helpers.HashToken(input.RefreshToken), claims.UserID).
+			First(&refreshToken).Error
+		if err == nil {
+			ac.revokeTokenFamily(c, refreshToken.FamilyID)
+		}
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAll revokes every access and refresh token of the current user
+func (ac *AuthController) LogoutAll(c *fiber.Ctx) error {
+	claims := helpers.GetAuthUser(c)
+	if claims == nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	if err := revokeUserSessions(requestDB(ac.DB, c), claims.UserID); err != nil {
+		return apperrors.Internal("Could not log out", err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Logged out from all sessions", nil)
+}
+
+// issueTokens creates an access token and a refresh token in the given family.
+// When previous is set it is marked as rotated; the update only succeeds if it
+// was still active, so concurrent use of the same token is treated as reuse.
+func (ac *AuthController) issueTokens(tx *gorm.DB, user *models.User, familyID string, previous *models.RefreshToken) (fiber.Map, error) {
+	accessToken, err := helpers.GenerateJWTToken(user.ID, user.Email, user.UserType)
+	if err != nil {
+		return nil, err
+	}
+
+	rawToken, tokenHash, err := helpers.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(helpers.RefreshTokenTTL()),
+	}
+	if err := tx.Create(&refreshToken).Error; err != nil {
+		return nil, err
+	}
+
+	if previous != nil {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", previous.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": refreshToken.ID,
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, errRefreshTokenReused
+		}
+	}
+
+	return fiber.Map{
+		"token":         accessToken,
+		"refresh_token": rawToken,
+		"expires_in":    int(helpers.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// revokeTokenFamily revokes every active refresh token sharing the family ID
+func (ac *AuthController) revokeTokenFamily(c *fiber.Ctx, familyID string) {
+	err := requestDB(ac.DB, c).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		helpers.WithRequest(c).Error("Failed to revoke refresh token family", err)
+	}
+}
+
+// errInvalidRefreshToken returns the error used for every refresh failure
+func errInvalidRefreshToken() error {
+	return apperrors.Unauthorized("Invalid or expired refresh token")
 }
 
 func (ac *AuthController) Register(c *fiber.Ctx) error {
 	input := new(requests.RegisterRequest)
 	if err := c.BodyParser(input); err != nil {
-		helpers.Error("Failed to parse registration input: %v", err)
-		return helpers.ErrorResponse(c, fiber.StatusBadRequest, "Invalid input", nil)
+		return apperrors.BadRequest("Invalid input").WithCause(err)
 	}
 
-	if errors := input.Validate(); len(errors) > 0 {
-		return helpers.ValidationErrorResponse(c, errors)
+	if err := validateInput(c, input); err != nil {
+		return err
 	}
 
+	// Public registration always creates employees; elevated roles are
+	// granted through invitations
 	user := models.User{
 		Name:     input.Name,
-		Email:    input.Email,
+		Email:    requests.NormalizeEmail(input.Email),
+		Phone:    phoneOrNil(input.Phone),
 		Password: input.Password,
-		UserType: models.Employee, // Default user type
-	}
-
-	// Set user type if provided
-	if input.UserType != "" {
-		switch input.UserType {
-		case "system_admin":
-			user.UserType = models.SystemAdmin
-		case "garments_admin":
-			user.UserType = models.GarmentsAdmin
-		case "department_admin":
-			user.UserType = models.DepartmentAdmin
-		case "employee":
-			user.UserType = models.Employee
-		default:
-			user.UserType = models.Employee
-		}
+		UserType: models.Employee,
 	}
 
 	if err := user.HashPassword(); err != nil {
-		return helpers.ServerErrorResponse(c, "Could not hash password")
+		return apperrors.Internal("Could not hash password", err)
 	}
 
-	if err := ac.DB.Create(&user).Error; err != nil {
-		return helpers.ServerErrorResponse(c, "Could not create user")
+	if err := requestDB(ac.DB, c).Create(&user).Error; err != nil {
+		if isUniqueViolation(err) {
+			return apperrors.Conflict("An account with this email or phone number already exists")
+		}
+		return apperrors.Internal("Could not create user", err)
 	}
 
 	user.Password = "" // Don't send password in response
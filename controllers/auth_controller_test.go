@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-template/config"
+	"go-fiber-template/helpers"
+	"go-fiber-template/models"
+	"go-fiber-template/testutil"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// useTestConfig installs a valid configuration for the duration of the test
+func useTestConfig(t *testing.T) {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Auth.JWTSecret = strings.Repeat("s", 32)
+	previous := config.Get()
+	config.Set(cfg)
+	t.Cleanup(func() { config.Set(previous) })
+}
+
+// rotationUpdate starts the statement marking a refresh token as rotated
+const rotationUpdate = `UPDATE "refresh_tokens" SET "replaced_by_id"`
+
+// refreshTokenHandler answers the statements of a token refresh. current is the
+// stored refresh token and rotated the rows affected by marking it as rotated.
+func refreshTokenHandler(current map[string]driver.Value, rotated int64) testutil.Handler {
+	return func(query testutil.Query) testutil.Result {
+		switch {
+		case strings.HasPrefix(query.SQL, `SELECT * FROM "refresh_tokens"`):
+			var columns []string
+			var values []driver.Value
+			for column, value := range current {
+				columns = append(columns, column)
+				values = append(values, value)
+			}
+			return testutil.Result{Columns: columns, Rows: [][]driver.Value{values}}
+		case strings.HasPrefix(query.SQL, `SELECT * FROM "users"`):
+			return testutil.Result{
+				Columns: []string{"id", "email", "user_type", "is_active"},
+				Rows:    [][]driver.Value{{int64(1), "jane@example.com", "employee", true}},
+			}
+		case strings.HasPrefix(query.SQL, `INSERT INTO "refresh_tokens"`):
+			return testutil.Result{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(8)}}}
+		case strings.HasPrefix(query.SQL, rotationUpdate):
+			return testutil.Result{RowsAffected: rotated}
+		}
+		return testutil.Result{RowsAffected: 1}
+	}
+}
+
+func TestIssueTokens(t *testing.T) {
+	useTestConfig(t)
+	user := &models.User{ID: 1, Email: "jane@example.com", UserType: models.Employee}
+
+	tests := []struct {
+		name      string
+		previous  *models.RefreshToken
+		insertErr error
+		rotated   int64
+		wantErr   error
+		wantQuery bool
+	}{
+		{name: "login starts a family", previous: nil},
+		{name: "rotation marks the previous token", previous: &models.RefreshToken{ID: 3}, rotated: 1, wantQuery: true},
+		{name: "rotated token is reuse", previous: &models.RefreshToken{ID: 3}, rotated: 0, wantErr: errRefreshTokenReused, wantQuery: true},
+		{name: "insert failure", previous: &models.RefreshToken{ID: 3}, insertErr: errors.New("connection lost")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewDB(t, func(query testutil.Query) testutil.Result {
+				if strings.HasPrefix(query.SQL, `INSERT INTO "refresh_tokens"`) {
+					if tt.insertErr != nil {
+						return testutil.Result{Err: tt.insertErr}
+					}
+					return testutil.Result{Columns: []string{"id"}, Rows: [][]driver.Value{{int64(8)}}}
+				}
+				return testutil.Result{RowsAffected: tt.rotated}
+			})
+			ac := &AuthController{DB: db.DB}
+
+			tokens, err := ac.issueTokens(db.DB, user, "family", tt.previous)
+			switch {
+			case tt.insertErr != nil:
+				if err == nil {
+					t.Fatal("issueTokens() succeeded, want the insert error")
+				}
+			case err != tt.wantErr:
+				t.Fatalf("issueTokens() error = %v, want %v", err, tt.wantErr)
+			}
+
+			rotations := db.Matching(rotationUpdate)
+			if got := len(rotations) > 0; got != tt.wantQuery {
+				t.Fatalf("rotation update sent = %v, want %v", got, tt.wantQuery)
+			}
+			if tt.wantQuery && !containsValue(rotations[0].Args, int64(8)) {
+				t.Errorf("rotation update args = %v, want replaced_by_id 8", rotations[0].Args)
+			}
+			if tt.wantErr != nil || tt.insertErr != nil {
+				return
+			}
+
+			raw, _ := tokens["refresh_token"].(string)
+			inserts := db.Matching(`INSERT INTO "refresh_tokens"`)
+			if raw == "" || len(inserts) != 1 || !containsValue(inserts[0].Args, helpers.HashToken(raw)) {
+				t.Errorf("refresh token %q is not stored by its hash: %v", raw, inserts)
+			}
+			if !containsValue(inserts[0].Args, "family") {
+				t.Errorf("refresh token args = %v, want family ID", inserts[0].Args)
+			}
+			if tokens["token"] == "" {
+				t.Error("access token is empty")
+			}
+		})
+	}
+}
+
+func TestRefreshReuseDetection(t *testing.T) {
+	useTestConfig(t)
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name              string
+		current           map[string]driver.Value
+		rotated           int64
+		wantStatus        int
+		wantFamilyRevoked bool
+	}{
+		{
+			name:       "active token rotates",
+			current:    map[string]driver.Value{"id": int64(3), "user_id": int64(1), "family_id": "family", "expires_at": time.Now().Add(time.Hour)},
+			rotated:    1,
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name:              "rotated token revokes the family",
+			current:           map[string]driver.Value{"id": int64(3), "user_id": int64(1), "family_id": "family", "expires_at": time.Now().Add(time.Hour), "revoked_at": revokedAt},
+			wantStatus:        fiber.StatusUnauthorized,
+			wantFamilyRevoked: true,
+		},
+		{
+			name:              "concurrent rotation revokes the family",
+			current:           map[string]driver.Value{"id": int64(3), "user_id": int64(1), "family_id": "family", "expires_at": time.Now().Add(time.Hour)},
+			rotated:           0,
+			wantStatus:        fiber.StatusUnauthorized,
+			wantFamilyRevoked: true,
+		},
+		{
+			name:       "expired token",
+			current:    map[string]driver.Value{"id": int64(3), "user_id": int64(1), "family_id": "family", "expires_at": time.Now().Add(-time.Hour)},
+			wantStatus: fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewDB(t, refreshTokenHandler(tt.current, tt.rotated))
+			ac := &AuthController{DB: db.DB}
+			app := fiber.New(fiber.Config{ErrorHandler: helpers.ErrorHandler})
+			app.Post("/refresh", ac.Refresh)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/refresh", strings.NewReader(`{"refresh_token":"raw-token"}`))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			familyRevoked := false
+			for _, query := range db.Matching(`UPDATE "refresh_tokens"`) {
+				if strings.Contains(query.SQL, "family_id = ") && containsValue(query.Args, "family") {
+					familyRevoked = true
+				}
+			}
+			if familyRevoked != tt.wantFamilyRevoked {
+				t.Errorf("family revoked = %v, want %v", familyRevoked, tt.wantFamilyRevoked)
+			}
+		})
+	}
+}
+
+// containsValue reports whether args contains value
+func containsValue(args []driver.Value, value driver.Value) bool {
+	for _, arg := range args {
+		if arg == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"go-fiber-template/apperrors"
+	"go-fiber-template/config"
+	"go-fiber-template/helpers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthController struct{}
+
+func NewHealthController() *HealthController {
+	return &HealthController{}
+}
+
+// Healthz reports that the process is alive without checking dependencies
+func (hc *HealthController) Healthz(c *fiber.Ctx) error {
+	return helpers.SuccessResponse(c, fiber.StatusOK, "OK", fiber.Map{
+		"status":         "alive",
+		"uptime_seconds": int64(helpers.Uptime().Seconds()),
+	})
+}
+
+// Readyz runs the registered dependency checks and returns 503 if any fails
+// or the application is shutting down
+func (hc *HealthController) Readyz(c *fiber.Ctx) error {
+	report := helpers.CheckReadiness(c.UserContext(), config.Get().Health.CheckTimeout)
+	if !report.Ready {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(helpers.Response{
+			Success:   false,
+			Code:      apperrors.CodeUnavailable,
+			Message:   "Service not ready",
+			Data:      report,
+			RequestID: helpers.GetRequestID(c),
+		})
+	}
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Service ready", report)
+}
@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"errors"
+	"go-fiber-template/apperrors"
+	"strings"
+	"time"
+
+	"go-fiber-template/helpers"
+	"go-fiber-template/models"
+	"go-fiber-template/requests"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// errInvitationUnavailable is returned when an invitation was accepted or revoked concurrently
+var errInvitationUnavailable = errors.New("invitation is no longer available")
+
+type InvitationController struct {
+	DB *gorm.DB
+}
+
+func NewInvitationController(db *gorm.DB) *InvitationController {
+	return &InvitationController{DB: db}
+}
+
+// Create issues an invitation for a role the inviter is allowed to grant
+func (ic *InvitationController) Create(c *fiber.Ctx) error {
+	input := new(requests.CreateInvitationRequest)
+	if err := c.BodyParser(input); err != nil {
+		return apperrors.BadRequest("Invalid input").WithCause(err)
+	}
+
+	if err := validateInput(c, input); err != nil {
+		return err
+	}
+
+	inviter, err := loadAuthUser(ic.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	role := models.UserType(input.UserType)
+	if !canGrantRole(inviter.UserType, role) {
+		return apperrors.Forbidden("You cannot invite users with this role")
+	}
+
+	department := strings.TrimSpace(input.Department)
+	if inviter.UserType == models.DepartmentAdmin {
+		// Department admins can only invite into their own department
+		department = inviter.Department
+	}
+	if role == models.DepartmentAdmin && department == "" {
+		return apperrors.Validation("Department is required for department admins")
+	}
+
+	email := requests.NormalizeEmail(input.Email)
+	var existing int64
+	if err := requestDB(ic.DB, c).Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&existing).Error; err != nil {
+		return apperrors.Internal("Could not create invitation", err)
+	}
+	if existing > 0 {
+		return apperrors.Conflict("A user with this email already exists")
+	}
+
+	rawToken, tokenHash, err := helpers.GenerateOpaqueToken()
+	if err != nil {
+		return apperrors.Internal("Could not create invitation", err)
+	}
+
+	invitation := models.Invitation{
+		Email:       email,
+		UserType:    role,
+		Department:  department,
+		TokenHash:   tokenHash,
+		InvitedByID: inviter.ID,
+		ExpiresAt:   time.Now().Add(helpers.InvitationTTL()),
+	}
+
+	err = requestDB(ic.DB, c).Transaction(func(tx *gorm.DB) error {
+		// A new invitation replaces any pending one for the same email
+		err := tx.Model(&models.Invitation{}).
+			Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL", email).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&invitation).Error
+	})
+	if err != nil {
+		return apperrors.Internal("Could not create invitation", err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Invitation created successfully", fiber.Map{
+		"invitation":   invitation,
+		"invite_token": rawToken,
+	})
+}
+
+// List returns invitations; system admins see all, others only their own
+func (ic *InvitationController) List(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(ic.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+	if !actor.UserType.AtLeast(models.DepartmentAdmin) {
+		return apperrors.Forbidden("You cannot manage invitations")
+	}
+
+	query := requestDB(ic.DB, c).Order("created_at DESC")
+	if actor.UserType != models.SystemAdmin {
+		query = query.Where("invited_by_id = ?", actor.ID)
+	}
+
+	var invitations []models.Invitation
+	if err := query.Find(&invitations).Error; err != nil {
+		return apperrors.Internal("Could not load invitations", err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Invitations retrieved successfully", invitations)
+}
+
+// Revoke cancels a pending invitation
+func (ic *InvitationController) Revoke(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(ic.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+	if !actor.UserType.AtLeast(models.DepartmentAdmin) {
+		return apperrors.Forbidden("You cannot manage invitations")
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return apperrors.BadRequest("Invalid input", "Invalid invitation ID")
+	}
+
+	var invitation models.Invitation
+	if err := requestDB(ic.DB, c).First(&invitation, id).Error; err != nil {
+		return apperrors.NotFound("Invitation not found")
+	}
+
+	if actor.UserType != models.SystemAdmin && invitation.InvitedByID != actor.ID {
+		return apperrors.NotFound("Invitation not found")
+	}
+
+	if !invitation.IsPending() {
+		return apperrors.Conflict("Invitation is no longer pending")
+	}
+
+	now := time.Now()
+	invitation.RevokedAt = &now
+	if err := requestDB(ic.DB, c).Model(&invitation).Update("revoked_at", now).Error; err != nil {
+		return apperrors.Internal("Could not revoke invitation", err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Invitation revoked successfully", invitation)
+}
+
+// Accept redeems an invitation token and creates the invited user
+func (ic *InvitationController) Accept(c *fiber.Ctx) error {
+	input := new(requests.AcceptInvitationRequest)
+	if err := c.BodyParser(input); err != nil {
+		return apperrors.BadRequest("Invalid input").WithCause(err)
+	}
+
+	if err := validateInput(c, input); err != nil {
+		return err
+	}
+
+	var invitation models.Invitation
+	if err := requestDB(ic.DB, c).Where("token_hash = ?", helpers.HashToken(input.Token)).First(&invitation).Error; err != nil {
+		return errInvalidInvitation()
+	}
+
+	if !invitation.IsPending() {
+		return errInvalidInvitation()
+	}
+
+	user := models.User{
+		Name:       input.Name,
+		Email:      requests.NormalizeEmail(invitation.Email),
+		Phone:      phoneOrNil(input.Phone),
+		Password:   input.Password,
+		UserType:   invitation.UserType,
+		Department: invitation.Department,
+	}
+
+	if err := user.HashPassword(); err != nil {
+		return apperrors.Internal("Could not hash password", err)
+	}
+
+	err := requestDB(ic.DB, c).Transaction(func(tx *gorm.DB) error {
+		// Claim the invitation first so that it can only be redeemed once
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvitationUnavailable
+		}
+		return tx.Create(&user).Error
+	})
+	if err == errInvitationUnavailable {
+		return errInvalidInvitation()
+	}
+	if isUniqueViolation(err) {
+		return apperrors.Conflict("An account with this email or phone number already exists")
+	}
+	if err != nil {
+		return apperrors.Internal("Could not create user", err)
+	}
+
+	user.Password = "" // Don't send password in response
+	return helpers.SuccessResponse(c, fiber.StatusCreated, "Invitation accepted successfully", user)
+}
+
+// canGrantRole reports whether a user with the inviter role may invite the target role.
+// System admins may grant any role; everyone else only roles below their own.
+func canGrantRole(inviter, target models.UserType) bool {
+	if !target.IsValid() {
+		return false
+	}
+	if inviter == models.SystemAdmin {
+		return true
+	}
+	return inviter.Level() > target.Level()
+}
+
+// errInvalidInvitation returns the error used for every invitation redemption failure
+func errInvalidInvitation() error {
+	return apperrors.BadRequest("Invalid invitation", "Invitation is invalid, expired or already used")
+}
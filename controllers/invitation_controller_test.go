@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"testing"
+
+	"go-fiber-template/models"
+)
+
+func TestCanGrantRole(t *testing.T) {
+	tests := []struct {
+		inviter models.UserType
+		target  models.UserType
+		want    bool
+	}{
+		{models.SystemAdmin, models.SystemAdmin, true},
+		{models.SystemAdmin, models.Employee, true},
+		{models.GarmentsAdmin, models.DepartmentAdmin, true},
+		{models.GarmentsAdmin, models.GarmentsAdmin, false},
+		{models.GarmentsAdmin, models.SystemAdmin, false},
+		{models.DepartmentAdmin, models.Employee, true},
+		{models.DepartmentAdmin, models.DepartmentAdmin, false},
+		{models.Employee, models.Employee, false},
+		{models.SystemAdmin, models.UserType("owner"), false},
+		{models.UserType("owner"), models.Employee, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.inviter)+" grants "+string(tt.target), func(t *testing.T) {
+			if got := canGrantRole(tt.inviter, tt.target); got != tt.want {
+				t.Errorf("canGrantRole(%q, %q) = %v, want %v", tt.inviter, tt.target, got, tt.want)
+			}
+		})
+	}
+}
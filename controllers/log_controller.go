@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"go-fiber-template/apperrors"
+	"strconv"
+	"time"
+
+	"go-fiber-template/helpers"
+	"go-fiber-template/models"
+	"go-fiber-template/requests"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLogLimit       = 50
+	defaultStatsWindow    = 24 * time.Hour
+	maxStatsRoutes        = 100
+	unmatchedRouteDisplay = "(unmatched)"
+)
+
+// LogStat represents aggregated request statistics for a route
+type LogStat struct {
+	Method       string  `json:"method"`
+	Route        string  `json:"route"`
+	Count        int64   `json:"count"`
+	ClientErrors int64   `json:"client_errors"`
+	ServerErrors int64   `json:"server_errors"`
+	ErrorRate    float64 `json:"error_rate"`
+	AvgMs        float64 `json:"avg_ms"`
+	P50Ms        float64 `json:"p50_ms"`
+	P95Ms        float64 `json:"p95_ms"`
+	P99Ms        float64 `json:"p99_ms"`
+}
+
+type LogController struct {
+	DB *gorm.DB
+}
+
+func NewLogController(db *gorm.DB) *LogController {
+	return &LogController{DB: db}
+}
+
+// Index returns audit records newest first using cursor pagination
+func (lc *LogController) Index(c *fiber.Ctx) error {
+	input, err := parseLogQuery(c)
+	if err != nil {
+		return err
+	}
+
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultLogLimit
+	}
+
+	query := applyLogFilters(requestDB(lc.DB, c).Model(&models.Log{}), input)
+	if input.Cursor != "" {
+		cursorID, ok := decodeLogCursor(input.Cursor)
+		if !ok {
+			return apperrors.Validation("Cursor is invalid")
+		}
+		query = query.Where("id < ?", cursorID)
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	var logs []models.Log
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&logs).Error; err != nil {
+		return apperrors.Internal("Could not load logs", err)
+	}
+
+	meta := fiber.Map{"limit": limit, "next_cursor": nil}
+	if len(logs) > limit {
+		logs = logs[:limit]
+		meta["next_cursor"] = encodeLogCursor(logs[len(logs)-1].ID)
+	}
+
+	return helpers.SuccessResponseWithMeta(c, fiber.StatusOK, "Logs retrieved successfully", logs, meta)
+}
+
+// Stats returns per-route counts, error rates and latency percentiles
+func (lc *LogController) Stats(c *fiber.Ctx) error {
+	input, err := parseLogQuery(c)
+	if err != nil {
+		return err
+	}
+
+	if input.From == "" && input.To == "" {
+		input.From = time.Now().Add(-defaultStatsWindow).Format(time.RFC3339)
+	}
+
+	var stats []LogStat
+	err = applyLogFilters(requestDB(lc.DB, c).Model(&models.Log{}), input).
+		Select(`method,
+			COALESCE(NULLIF(route, ''), ?) AS route,
+			COUNT(*) AS count,
+			COUNT(*) FILTER (WHERE status_code >= 400 AND status_code < 500) AS client_errors,
+			COUNT(*) FILTER (WHERE status_code >= 500) AS server_errors,
+			COALESCE(AVG(duration_ms), 0) AS avg_ms,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY duration_ms), 0) AS p50_ms,
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms), 0) AS p95_ms,
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY duration_ms), 0) AS p99_ms`, unmatchedRouteDisplay).
+		Group("1, 2"). // method and route
+		Order("count DESC").
+		Limit(maxStatsRoutes).
+		Scan(&stats).Error
+	if err != nil {
+		return apperrors.Internal("Could not load log statistics", err)
+	}
+
+	for i := range stats {
+		if stats[i].Count > 0 {
+			stats[i].ErrorRate = float64(stats[i].ClientErrors+stats[i].ServerErrors) / float64(stats[i].Count)
+		}
+	}
+
+	return helpers.SuccessResponseWithMeta(c, fiber.StatusOK, "Log statistics retrieved successfully", stats, fiber.Map{
+		"from": input.From,
+		"to":   input.To,
+	})
+}
+
+// parseLogQuery parses and validates the log filters from the query string
+func parseLogQuery(c *fiber.Ctx) (*requests.LogQuery, error) {
+	input := new(requests.LogQuery)
+	if err := c.QueryParser(input); err != nil {
+		return nil, apperrors.BadRequest("Invalid input", "Invalid query parameters").WithCause(err)
+	}
+
+	if err := validateInput(c, input); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// applyLogFilters applies the log query filters shared by Index and Stats
+func applyLogFilters(query *gorm.DB, input *requests.LogQuery) *gorm.DB {
+	if input.Method != "" {
+		query = query.Where("method = ?", input.Method)
+	}
+	if input.StatusMin > 0 {
+		query = query.Where("status_code >= ?", input.StatusMin)
+	}
+	if input.StatusMax > 0 {
+		query = query.Where("status_code <= ?", input.StatusMax)
+	}
+	if input.URLPrefix != "" {
+		query = query.Where("url LIKE ?", escapeLike(input.URLPrefix)+"%")
+	}
+	if from := input.FromTime(); !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if to := input.ToTime(); !to.IsZero() {
+		query = query.Where("created_at < ?", to)
+	}
+	if input.UserID > 0 {
+		query = query.Where("user_id = ?", input.UserID)
+	}
+	if input.RequestID != "" {
+		query = query.Where("request_id = ?", input.RequestID)
+	}
+	return query
+}
+
+// encodeLogCursor encodes the ID of the last returned row as an opaque cursor
+func encodeLogCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+// decodeLogCursor decodes a cursor produced by encodeLogCursor
+func decodeLogCursor(cursor string) (uint, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
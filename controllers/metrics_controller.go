@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"bytes"
+
+	"go-fiber-template/helpers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MetricsController struct{}
+
+func NewMetricsController() *MetricsController {
+	return &MetricsController{}
+}
+
+// Show writes every registered metric in the Prometheus text exposition format
+func (mc *MetricsController) Show(c *fiber.Ctx) error {
+	var buf bytes.Buffer
+	helpers.Metrics().WriteText(&buf)
+
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	return c.Send(buf.Bytes())
+}
@@ -0,0 +1,421 @@
+package controllers
+
+import (
+	"go-fiber-template/apperrors"
+	"strings"
+	"time"
+
+	"go-fiber-template/helpers"
+	"go-fiber-template/models"
+	"go-fiber-template/requests"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUsersPerPage = 15
+)
+
+// managedUser is a user as the admins managing it see it, with the login lockout
+// state that other responses leave out
+type managedUser struct {
+	*models.User
+	FailedLoginAttempts int        `json:"failed_login_attempts"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty"`
+}
+
+// newManagedUser returns the admin view of a user
+func newManagedUser(user *models.User) managedUser {
+	return managedUser{User: user, FailedLoginAttempts: user.FailedLoginAttempts, LockedUntil: user.LockedUntil}
+}
+
+type UserController struct {
+	DB *gorm.DB
+}
+
+func NewUserController(db *gorm.DB) *UserController {
+	return &UserController{DB: db}
+}
+
+// Profile returns the authenticated user
+func (uc *UserController) Profile(c *fiber.Ctx) error {
+	user, err := loadAuthUser(uc.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "Profile retrieved successfully", user)
+}
+
+// List returns a page of users the authenticated admin may manage
+func (uc *UserController) List(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(uc.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	input := new(requests.UserListQuery)
+	if err := c.QueryParser(input); err != nil {
+		return apperrors.BadRequest("Invalid input").WithCause(err)
+	}
+
+	if err := validateInput(c, input); err != nil {
+		return err
+	}
+
+	page := input.Page
+	if page == 0 {
+		page = 1
+	}
+	perPage := input.PerPage
+	if perPage == 0 {
+		perPage = defaultUsersPerPage
+	}
+
+	query := scopeUsers(requestDB(uc.DB, c).Model(&models.User{}), actor)
+
+	switch input.Trashed {
+	case "with":
+		query = query.Unscoped()
+	case "only":
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if input.UserType != "" {
+		query = query.Where("user_type = ?", input.UserType)
+	}
+	if input.IsActive != "" {
+		query = query.Where("is_active = ?", input.IsActive == "true")
+	}
+	if search := strings.TrimSpace(input.Search); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR phone ILIKE ?", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return apperrors.Internal("Could not load users", err)
+	}
+
+	var users []models.User
+	err = query.Order("id ASC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&users).Error
+	if err != nil {
+		return apperrors.Internal("Could not load users", err)
+	}
+
+	managed := make([]managedUser, len(users))
+	for i := range users {
+		managed[i] = newManagedUser(&users[i])
+	}
+
+	return helpers.PaginatedResponse(c, "Users retrieved successfully", managed, helpers.NewPagination(page, perPage, total))
+}
+
+// Show returns a single user the authenticated admin may manage
+func (uc *UserController) Show(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(uc.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	user, ok := uc.findManagedUser(c, actor, false)
+	if !ok {
+		return apperrors.NotFound("User not found")
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "User retrieved successfully", newManagedUser(user))
+}
+
+// Update changes the profile, role, department or status of a managed user
+func (uc *UserController) Update(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(uc.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	user, ok := uc.findManagedUser(c, actor, false)
+	if !ok {
+		return apperrors.NotFound("User not found")
+	}
+
+	// Admins cannot lock themselves out, which could leave no system admin
+	if user.ID == actor.ID {
+		return apperrors.Forbidden("You cannot update your own account")
+	}
+
+	input := new(requests.UpdateUserRequest)
+	if err := c.BodyParser(input); err != nil {
+		return apperrors.BadRequest("Invalid input").WithCause(err)
+	}
+
+	if err := validateInput(c, input); err != nil {
+		return err
+	}
+
+	// Access tokens carry the role and are trusted until they expire, so changing
+	// the role, department or status ends the user's sessions
+	revokeSessions := false
+	updates := map[string]interface{}{}
+	if input.Name != nil {
+		updates["name"] = strings.TrimSpace(*input.Name)
+	}
+	if input.Phone != nil {
+		// An empty phone number removes it
+		updates["phone"] = phoneOrNil(*input.Phone)
+	}
+	if input.Avatar != nil {
+		updates["avatar"] = *input.Avatar
+	}
+	if input.UserType != nil {
+		role := models.UserType(*input.UserType)
+		if role != user.UserType && !canGrantRole(actor.UserType, role) {
+			return apperrors.Forbidden("You cannot assign this role")
+		}
+		updates["user_type"] = role
+		revokeSessions = revokeSessions || role != user.UserType
+	}
+	if input.Department != nil {
+		department := strings.TrimSpace(*input.Department)
+		// Department admins cannot move staff out of their own department
+		if actor.UserType == models.DepartmentAdmin && department != actor.Department {
+			return apperrors.Forbidden("You cannot change the department of this user")
+		}
+		updates["department"] = department
+		revokeSessions = revokeSessions || department != user.Department
+	}
+	if input.IsActive != nil {
+		updates["is_active"] = *input.IsActive
+		revokeSessions = revokeSessions || !*input.IsActive
+	}
+
+	if len(updates) == 0 {
+		return helpers.SuccessResponse(c, fiber.StatusOK, "User updated successfully", newManagedUser(user))
+	}
+
+	if err := requestDB(uc.DB, c).Model(user).Updates(updates).Error; err != nil {
+		if isUniqueViolation(err) {
+			return apperrors.Conflict("Phone number is already in use")
+		}
+		return apperrors.Internal("Could not update user", err)
+	}
+
+	if revokeSessions {
+		if err := revokeUserSessions(requestDB(uc.DB, c), user.ID); err != nil {
+			helpers.WithRequest(c).Error("Failed to revoke sessions of updated user", err)
+		}
+	}
+
+	if err := requestDB(uc.DB, c).First(user, user.ID).Error; err != nil {
+		return apperrors.Internal("Could not load user", err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "User updated successfully", newManagedUser(user))
+}
+
+// Deactivate disables a managed user and revokes their sessions
+func (uc *UserController) Deactivate(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(uc.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	user, ok := uc.findManagedUser(c, actor, false)
+	if !ok {
+		return apperrors.NotFound("User not found")
+	}
+
+	// Admins cannot lock themselves out, which could leave no system admin
+	if user.ID == actor.ID {
+		return apperrors.Forbidden("You cannot deactivate your own account")
+	}
+
+	if err := requestDB(uc.DB, c).Model(user).Update("is_active", false).Error; err != nil {
+		return apperrors.Internal("Could not deactivate user", err)
+	}
+	user.IsActive = false
+
+	if err := revokeUserSessions(requestDB(uc.DB, c), user.ID); err != nil {
+		helpers.WithRequest(c).Error("Failed to revoke sessions of deactivated user", err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "User deactivated successfully", newManagedUser(user))
+}
+
+// Delete soft-deletes a managed user and revokes their sessions
+func (uc *UserController) Delete(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(uc.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	user, ok := uc.findManagedUser(c, actor, false)
+	if !ok {
+		return apperrors.NotFound("User not found")
+	}
+
+	// Admins cannot lock themselves out, which could leave no system admin
+	if user.ID == actor.ID {
+		return apperrors.Forbidden("You cannot delete your own account")
+	}
+
+	if err := requestDB(uc.DB, c).Delete(user).Error; err != nil {
+		return apperrors.Internal("Could not delete user", err)
+	}
+
+	if err := revokeUserSessions(requestDB(uc.DB, c), user.ID); err != nil {
+		helpers.WithRequest(c).Error("Failed to revoke sessions of deleted user", err)
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "User deleted successfully", nil)
+}
+
+// Restore brings back a soft-deleted managed user
+func (uc *UserController) Restore(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(uc.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	user, ok := uc.findManagedUser(c, actor, true)
+	if !ok || !user.DeletedAt.Valid {
+		return apperrors.NotFound("Deleted user not found")
+	}
+
+	if err := requestDB(uc.DB, c).Unscoped().Model(user).Update("deleted_at", nil).Error; err != nil {
+		return apperrors.Internal("Could not restore user", err)
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	return helpers.SuccessResponse(c, fiber.StatusOK, "User restored successfully", newManagedUser(user))
+}
+
+// Unlock lifts a login lockout of a managed user and clears their failed attempts
+func (uc *UserController) Unlock(c *fiber.Ctx) error {
+	actor, err := loadAuthUser(uc.DB, c)
+	if err != nil {
+		return apperrors.Unauthorized("Invalid credentials")
+	}
+
+	user, ok := uc.findManagedUser(c, actor, false)
+	if !ok {
+		return apperrors.NotFound("User not found")
+	}
+
+	if err := clearLoginFailures(requestDB(uc.DB, c), user); err != nil {
+		return apperrors.Internal("Could not unlock user", err)
+	}
+
+	if limiter := helpers.LoginAccountLimiter(); limiter != nil {
+		identifiers := []string{user.Email}
+		if user.Phone != nil {
+			identifiers = append(identifiers, *user.Phone)
+		}
+		for _, identifier := range identifiers {
+			if err := limiter.Reset(c.UserContext(), helpers.LoginAccountKey(identifier)); err != nil {
+				helpers.WithRequest(c).Error("Failed to reset login rate limit of unlocked user", err)
+			}
+		}
+	}
+
+	return helpers.SuccessResponse(c, fiber.StatusOK, "User unlocked successfully", newManagedUser(user))
+}
+
+// findManagedUser loads the user from the :id parameter if the actor may manage it
+func (uc *UserController) findManagedUser(c *fiber.Ctx, actor *models.User, withTrashed bool) (*models.User, bool) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return nil, false
+	}
+
+	query := scopeUsers(requestDB(uc.DB, c), actor)
+	if withTrashed {
+		query = query.Unscoped()
+	}
+
+	var user models.User
+	if err := query.First(&user, id).Error; err != nil {
+		return nil, false
+	}
+	return &user, true
+}
+
+// scopeUsers restricts a user query to the users the actor may manage: system
+// admins manage everyone, other admins only roles below their own, and
+// department admins only their own department's staff
+func scopeUsers(query *gorm.DB, actor *models.User) *gorm.DB {
+	if actor.UserType == models.SystemAdmin {
+		return query
+	}
+
+	query = query.Where("user_type IN ?", actor.UserType.Below())
+	if actor.UserType == models.DepartmentAdmin {
+		query = query.Where("department = ?", actor.Department)
+	}
+	return query
+}
+
+// loadAuthUser loads the authenticated user from the database
+func loadAuthUser(db *gorm.DB, c *fiber.Ctx) (*models.User, error) {
+	claims := helpers.GetAuthUser(c)
+	if claims == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var user models.User
+	if err := requestDB(db, c).First(&user, claims.UserID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// validateInput validates a request with messages in the client's preferred language
+func validateInput(c *fiber.Ctx, input interface{}) error {
+	if fields := requests.Validate(input, requests.MatchLocale(c.Get(fiber.HeaderAcceptLanguage))); len(fields) > 0 {
+		return apperrors.InvalidFields(fields)
+	}
+	return nil
+}
+
+// requestDB binds the request context to db so queries carry the request ID
+func requestDB(db *gorm.DB, c *fiber.Ctx) *gorm.DB {
+	return db.WithContext(c.UserContext())
+}
+
+// phoneOrNil normalizes a validated phone number to E.164, or returns nil when it is empty
+func phoneOrNil(raw string) *string {
+	phone, err := requests.NormalizePhone(raw)
+	if err != nil {
+		return nil
+	}
+	return &phone
+}
+
+// escapeLike escapes LIKE wildcards in user supplied search terms
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
+
+// isUniqueViolation reports whether the error is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "SQLSTATE 23505") ||
+		strings.Contains(err.Error(), "duplicate key"))
+}
+
+// revokeUserSessions revokes every access and refresh token of a user,
+// using the context bound to db
+func revokeUserSessions(db *gorm.DB, userID uint) error {
+	if store := helpers.GetRevocationStore(); store != nil {
+		if err := store.RevokeAllForUser(db.Statement.Context, userID); err != nil {
+			return err
+		}
+	}
+
+	return db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
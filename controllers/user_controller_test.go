@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"database/sql/driver"
+	"slices"
+	"testing"
+
+	"go-fiber-template/models"
+	"go-fiber-template/testutil"
+)
+
+func TestScopeUsers(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    models.User
+		wantSQL  string
+		wantArgs []driver.Value
+	}{
+		{
+			name:    "system admin manages everyone",
+			actor:   models.User{UserType: models.SystemAdmin, Department: "it"},
+			wantSQL: `SELECT * FROM "users" WHERE "users"."deleted_at" IS NULL`,
+		},
+		{
+			name:     "garments admin manages lower roles in every department",
+			actor:    models.User{UserType: models.GarmentsAdmin, Department: "sewing"},
+			wantSQL:  `SELECT * FROM "users" WHERE user_type IN ($1,$2) AND "users"."deleted_at" IS NULL`,
+			wantArgs: []driver.Value{"employee", "department_admin"},
+		},
+		{
+			name:     "department admin manages employees of their department",
+			actor:    models.User{UserType: models.DepartmentAdmin, Department: "cutting"},
+			wantSQL:  `SELECT * FROM "users" WHERE user_type IN ($1) AND department = $2 AND "users"."deleted_at" IS NULL`,
+			wantArgs: []driver.Value{"employee", "cutting"},
+		},
+		{
+			name:    "employee manages nobody",
+			actor:   models.User{UserType: models.Employee, Department: "cutting"},
+			wantSQL: `SELECT * FROM "users" WHERE user_type IN (NULL) AND "users"."deleted_at" IS NULL`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewDB(t, nil)
+
+			var users []models.User
+			if err := scopeUsers(db.Model(&models.User{}), &tt.actor).Find(&users).Error; err != nil {
+				t.Fatalf("query failed: %v", err)
+			}
+
+			queries := db.Queries()
+			if len(queries) != 1 {
+				t.Fatalf("got %d queries, want 1", len(queries))
+			}
+			if queries[0].SQL != tt.wantSQL {
+				t.Errorf("SQL = %s\nwant  %s", queries[0].SQL, tt.wantSQL)
+			}
+			if !slices.Equal(queries[0].Args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", queries[0].Args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"reflect"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,6 +14,8 @@ import (
 	"go-fiber-template/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
 )
 
 // ModelInfo represents information about a database model
@@ -17,6 +23,8 @@ type ModelInfo struct {
 	TableName string
 	Model     interface{}
 	Fields    []FieldInfo
+	// Indexes are the indexes the model declares in tags and its Indexes method
+	Indexes []IndexInfo
 }
 
 // FieldInfo represents information about a model field
@@ -42,13 +50,17 @@ type FieldInfo struct {
 
 // MigrationOperation represents a database migration operation
 type MigrationOperation struct {
-	Type        string // "add_column", "drop_column", "modify_column", "add_index", "drop_index", "add_constraint", "drop_constraint"
+	Type        string // "create_table", "add_column", "drop_column", "modify_column", "add_index", "drop_index", "add_constraint", "drop_constraint"
 	TableName   string
 	ColumnName  string
 	OldField    *FieldInfo
 	NewField    *FieldInfo
 	SQL         string
 	Description string
+	// Index fields
+	IndexName    string
+	IndexColumns []string
+	Unique       bool
 	// Foreign key constraint fields
 	ConstraintName   string
 	ReferencedTable  string
@@ -68,46 +80,49 @@ type ColumnInfo struct {
 
 // DynamicMigrator handles dynamic database migrations
 type DynamicMigrator struct {
-	db     *gorm.DB
-	models []ModelInfo
+	db               *gorm.DB
+	models           []ModelInfo
+	allowDropColumns bool
 }
 
-// NewDynamicMigrator creates a new dynamic migrator instance
-func NewDynamicMigrator(db *gorm.DB) *DynamicMigrator {
+// NewDynamicMigrator creates a new dynamic migrator instance for the registered models
+func NewDynamicMigrator(db *gorm.DB) (*DynamicMigrator, error) {
+	modelInfos, err := getRegisteredModels(db)
+	if err != nil {
+		return nil, err
+	}
 	return &DynamicMigrator{
 		db:     db,
-		models: getRegisteredModels(),
-	}
+		models: modelInfos,
+	}, nil
 }
 
-// getRegisteredModels returns all registered models for migration
-func getRegisteredModels() []ModelInfo {
-	modelsToRegister := []interface{}{
-		// Core models
-		&models.User{},
-		&models.Log{},
-		// Add other models here as they are created
-	}
+// AllowDropColumns lets ExecuteMigrations drop columns that no model field maps to.
+// It is off by default because dropping a column destroys its data.
+func (dm *DynamicMigrator) AllowDropColumns(allow bool) {
+	dm.allowDropColumns = allow
+}
 
-	var modelInfos []ModelInfo
-	for _, model := range modelsToRegister {
-		modelInfo := extractModelInfo(model)
-		modelInfos = append(modelInfos, modelInfo)
+// getRegisteredModels returns the models registered with models.Register in migration order
+func getRegisteredModels(db *gorm.DB) ([]ModelInfo, error) {
+	entries, err := orderedModels(db)
+	if err != nil {
+		return nil, err
 	}
 
-	return modelInfos
+	modelInfos := make([]ModelInfo, 0, len(entries))
+	for _, entry := range entries {
+		modelInfos = append(modelInfos, extractModelInfo(entry.Schema, entry.Model))
+	}
+	return modelInfos, nil
 }
 
 // RunSerialMigrations runs migrations for each model serially with detailed logging
 func RunSerialMigrations(db *gorm.DB) error {
-	// Define models in migration order (dependencies first)
-	modelMigrations := []struct {
-		Name  string
-		Model interface{}
-	}{
-		{"User", &models.User{}},
-		{"Log", &models.Log{}},
-		// Add other models here in dependency order
+	// Registered models in migration order (dependencies first)
+	modelMigrations, err := orderedModels(db)
+	if err != nil {
+		return err
 	}
 
 	// Check which models need migration and what type of migration
@@ -186,32 +201,8 @@ func getMigrationType(db *gorm.DB, model interface{}, modelName string) string {
 		existingColumns[col.Name()] = true
 	}
 
-	// Check if all model fields exist as columns
-	modelType := reflect.TypeOf(model).Elem()
-	for i := 0; i < modelType.NumField(); i++ {
-		field := modelType.Field(i)
-
-		// Skip unexported fields
-		if !field.IsExported() {
-			continue
-		}
-
-		// Skip embedded structs (like gorm.Model)
-		if field.Anonymous {
-			continue
-		}
-
-		gormTag := field.Tag.Get("gorm")
-
-		// Skip fields marked with gorm:"-" or association fields
-		if gormTag == "-" || isGormAssociationField(field, gormTag) {
-			continue
-		}
-
-		// Get column name
-		columnName := getFieldName(field.Name, gormTag)
-
-		// If column doesn't exist, it's an update
+	// Check if every column GORM maps the model to exists, including embedded fields
+	for _, columnName := range stmt.Schema.DBNames {
 		if !existingColumns[columnName] {
 			return "update"
 		}
@@ -244,32 +235,8 @@ func needsMigration(db *gorm.DB, model interface{}, modelName string) bool {
 		existingColumns[col.Name()] = true
 	}
 
-	// Check if all model fields exist as columns
-	modelType := reflect.TypeOf(model).Elem()
-	for i := 0; i < modelType.NumField(); i++ {
-		field := modelType.Field(i)
-
-		// Skip unexported fields
-		if !field.IsExported() {
-			continue
-		}
-
-		// Skip embedded structs (like gorm.Model)
-		if field.Anonymous {
-			continue
-		}
-
-		gormTag := field.Tag.Get("gorm")
-
-		// Skip fields marked with gorm:"-" or association fields
-		if gormTag == "-" || isGormAssociationField(field, gormTag) {
-			continue
-		}
-
-		// Get column name
-		columnName := getFieldName(field.Name, gormTag)
-
-		// If column doesn't exist, migration is needed
+	// Check if every column GORM maps the model to exists, including embedded fields
+	for _, columnName := range stmt.Schema.DBNames {
 		if !existingColumns[columnName] {
 			return true
 		}
@@ -279,245 +246,89 @@ func needsMigration(db *gorm.DB, model interface{}, modelName string) bool {
 }
 
 // GetRegisteredModels is a public wrapper for getRegisteredModels
-func GetRegisteredModels() []ModelInfo {
-	return getRegisteredModels()
+func GetRegisteredModels() ([]ModelInfo, error) {
+	return getRegisteredModels(DB)
 }
 
-// extractModelInfo extracts field information from a model
-func extractModelInfo(model interface{}) ModelInfo {
+// extractModelInfo extracts field information from a model and its GORM schema
+func extractModelInfo(modelSchema *schema.Schema, model interface{}) ModelInfo {
 	modelType := reflect.TypeOf(model).Elem()
 
-	// Get table name from GORM
-	stmt := &gorm.Statement{DB: DB}
-	stmt.Parse(model)
-	tableName := stmt.Schema.Table
-
 	var fields []FieldInfo
+	seen := make(map[string]bool)
 
-	for i := 0; i < modelType.NumField(); i++ {
-		field := modelType.Field(i)
-
-		// Skip unexported fields
-		if !field.IsExported() {
-			continue
-		}
-
-		// Skip embedded structs that are not database fields
-		if field.Anonymous {
-			continue
-		}
-
-		fieldInfo := extractFieldInfo(field)
-		if fieldInfo.Name != "" {
+	for _, field := range modelFields(modelType) {
+		fieldInfo := extractFieldInfo(modelSchema, field)
+		if fieldInfo.Name != "" && !seen[fieldInfo.Name] {
+			seen[fieldInfo.Name] = true
 			fields = append(fields, fieldInfo)
 		}
 	}
 
-	// Extract foreign key relationships from struct fields
-	extractForeignKeyRelationships(modelType, &fields, tableName)
+	// Extract foreign key relationships from the associations
+	extractForeignKeyRelationships(modelSchema, fields)
 
 	return ModelInfo{
-		TableName: tableName,
+		TableName: modelSchema.Table,
 		Model:     model,
 		Fields:    fields,
+		Indexes:   declaredIndexes(modelSchema, model, fields),
 	}
 }
 
-// toSnakeCase converts CamelCase to snake_case
-func toSnakeCase(str string) string {
-	// Handle common special cases for full words
-	switch str {
-	case "ID":
-		return "id"
-	case "URL":
-		return "url"
-	case "API":
-		return "api"
-	case "HTTP":
-		return "http"
-	case "JSON":
-		return "json"
-	case "XML":
-		return "xml"
-	case "SQL":
-		return "sql"
-	case "UUID":
-		return "uuid"
-	}
-
-	// Handle cases where common acronyms are at the end of a word
-	acronyms := []string{"ID", "URL", "API", "HTTP", "JSON", "XML", "SQL", "UUID"}
-	for _, acronym := range acronyms {
-		if strings.HasSuffix(str, acronym) && len(str) > len(acronym) {
-			prefix := str[:len(str)-len(acronym)]
-			return toSnakeCase(prefix) + "_" + strings.ToLower(acronym)
-		}
-	}
-
-	// Handle cases where common acronyms are at the beginning of a word
-	for _, acronym := range acronyms {
-		if strings.HasPrefix(str, acronym) && len(str) > len(acronym) {
-			suffix := str[len(acronym):]
-			// Check if the next character is uppercase (indicating start of new word)
-			if len(suffix) > 0 && suffix[0] >= 'A' && suffix[0] <= 'Z' {
-				return strings.ToLower(acronym) + "_" + toSnakeCase(suffix)
-			}
-		}
-	}
-
-	var result strings.Builder
-	for i, r := range str {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			result.WriteRune('_')
-		}
-		if r >= 'A' && r <= 'Z' {
-			result.WriteRune(r - 'A' + 'a')
-		} else {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
-}
-
-// extractForeignKeyRelationships extracts foreign key relationships from model struct
-func extractForeignKeyRelationships(modelType reflect.Type, fields *[]FieldInfo, _ string) {
-	// Map to store table name mappings for different models
-	tableNameMap := map[string]string{
-		"User": "users",
-		"Log":  "logs",
-		// Add other models here as they are created
-	}
-
+// modelFields returns the exported fields of a model with embedded structs such as
+// gorm.Model expanded after its own fields, so fields declared on the model win
+func modelFields(modelType reflect.Type) []reflect.StructField {
+	var fields, embedded []reflect.StructField
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
-		gormTag := field.Tag.Get("gorm")
 
-		// Skip if no gorm tag or marked as ignored
-		if gormTag == "" || gormTag == "-" {
+		// Skip unexported fields
+		if !field.IsExported() {
 			continue
 		}
 
-		// Skip association fields - we only want to process the foreign key fields
-		if isGormAssociationField(field, gormTag) {
-			// Parse this association field to find foreign key relationships
-			var foreignKeyColumn, referencedTable, referencedColumn, onUpdate, onDelete string
-
-			// Extract foreign key column name and constraint details
-			tags := strings.Split(gormTag, ";")
-			for _, tag := range tags {
-				tag = strings.TrimSpace(tag)
-				if strings.HasPrefix(tag, "foreignKey:") {
-					foreignKeyColumn = strings.TrimPrefix(tag, "foreignKey:")
-				} else if strings.HasPrefix(tag, "constraint:") {
-					constraintStr := strings.TrimPrefix(tag, "constraint:")
-					parts := strings.Split(constraintStr, ",")
-					for _, part := range parts {
-						part = strings.TrimSpace(part)
-						if strings.HasPrefix(part, "OnUpdate:") {
-							onUpdate = strings.TrimPrefix(part, "OnUpdate:")
-						} else if strings.HasPrefix(part, "OnDelete:") {
-							onDelete = strings.TrimPrefix(part, "OnDelete:")
-						}
-					}
-				}
-			}
-
-			if foreignKeyColumn != "" {
-				// Determine referenced table from field type
-				fieldTypeName := field.Type.String()
-				if strings.Contains(fieldTypeName, "*") {
-					fieldTypeName = strings.TrimPrefix(fieldTypeName, "*")
-				}
-				if strings.Contains(fieldTypeName, "[]") {
-					fieldTypeName = strings.TrimPrefix(fieldTypeName, "[]")
-				}
-
-				// Extract type name from package.Type format
-				if dotIndex := strings.LastIndex(fieldTypeName, "."); dotIndex != -1 {
-					fieldTypeName = fieldTypeName[dotIndex+1:]
-				}
-
-				// Get referenced table name
-				if mappedTable, exists := tableNameMap[fieldTypeName]; exists {
-					referencedTable = mappedTable
-				} else {
-					// Default conversion: convert to snake_case and pluralize
-					referencedTable = toSnakeCase(fieldTypeName) + "s"
-				}
-
-				// Default referenced column is usually 'id'
-				referencedColumn = "id"
-
-				// Find the foreign key field in our fields slice and update it
-				foreignKeyFieldName := toSnakeCase(foreignKeyColumn)
-				for j, f := range *fields {
-					if f.Name == foreignKeyFieldName {
-						(*fields)[j].ReferencedTable = referencedTable
-						(*fields)[j].ReferencedColumn = referencedColumn
-						(*fields)[j].OnUpdate = onUpdate
-						(*fields)[j].OnDelete = onDelete
-						break
-					}
-				}
-			}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, modelFields(field.Type)...)
+			continue
 		}
+		fields = append(fields, field)
 	}
+	return append(fields, embedded...)
 }
 
-// isGormAssociationField checks if a field is a GORM association (relationship) field
-func isGormAssociationField(field reflect.StructField, gormTag string) bool {
-	// Check if the field type is a struct or slice of structs (associations)
-	fieldType := field.Type
-
-	// Handle pointer types
-	if fieldType.Kind() == reflect.Ptr {
-		fieldType = fieldType.Elem()
-	}
-
-	// Handle slice types (for has-many relationships)
-	if fieldType.Kind() == reflect.Slice {
-		fieldType = fieldType.Elem()
-		// Handle slice of pointers
-		if fieldType.Kind() == reflect.Ptr {
-			fieldType = fieldType.Elem()
-		}
-	}
-
-	// If the field type is a struct (not a basic type), it's likely an association
-	if fieldType.Kind() == reflect.Struct {
-		// Exclude time.Time which is a struct but should be stored as a database column
-		if fieldType == reflect.TypeOf(time.Time{}) {
-			return false
-		}
-
-		// Check if it has foreignKey tag (indicates this is an association field)
-		if strings.Contains(gormTag, "foreignKey:") {
-			return true
-		}
-
-		// Check for other association indicators
-		if strings.Contains(gormTag, "references:") ||
-			strings.Contains(gormTag, "many2many:") ||
-			strings.Contains(gormTag, "polymorphic:") ||
-			strings.Contains(gormTag, "joinForeignKey:") {
-			return true
+// extractForeignKeyRelationships sets the referenced table, column and actions of the
+// foreign key fields of a model's associations as GORM's schema parser resolves them.
+// Only single-column foreign keys on the model's own table are handled.
+func extractForeignKeyRelationships(modelSchema *schema.Schema, fields []FieldInfo) {
+	for _, relationship := range modelSchema.Relationships.Relations {
+		constraint := relationship.ParseConstraint()
+		if constraint == nil || constraint.Schema == nil || constraint.Schema.Table != modelSchema.Table ||
+			len(constraint.ForeignKeys) != 1 {
+			continue
 		}
 
-		// If it's a struct type from our models packages, it's likely an association
-		typeName := fieldType.String()
-		if strings.Contains(typeName, "models.User") ||
-			strings.Contains(typeName, "models.Log") {
-			return true
+		for i := range fields {
+			if fields[i].Name == constraint.ForeignKeys[0].DBName {
+				fields[i].ReferencedTable = constraint.ReferenceSchema.Table
+				fields[i].ReferencedColumn = constraint.References[0].DBName
+				fields[i].OnUpdate = constraint.OnUpdate
+				fields[i].OnDelete = constraint.OnDelete
+				break
+			}
 		}
-
-		return true // Default: if it's a struct, treat as association
 	}
+}
 
-	return false
+// isGormAssociationField checks if a field is a GORM association (relationship) field
+// rather than a column, using the relationships GORM parsed for the model
+func isGormAssociationField(modelSchema *schema.Schema, field reflect.StructField) bool {
+	_, ok := modelSchema.Relationships.Relations[field.Name]
+	return ok
 }
 
 // extractFieldInfo extracts field information from a struct field
-func extractFieldInfo(field reflect.StructField) FieldInfo {
+func extractFieldInfo(modelSchema *schema.Schema, field reflect.StructField) FieldInfo {
 	gormTag := field.Tag.Get("gorm")
 	jsonTag := field.Tag.Get("json")
 
@@ -527,13 +338,12 @@ func extractFieldInfo(field reflect.StructField) FieldInfo {
 	}
 
 	// Skip GORM association fields (relationships)
-	if isGormAssociationField(field, gormTag) {
+	if isGormAssociationField(modelSchema, field) {
 		return FieldInfo{}
 	}
 
 	fieldInfo := FieldInfo{
 		Name:    getFieldName(field.Name, gormTag),
-		Type:    getFieldType(field.Type, gormTag),
 		GormTag: gormTag,
 		JsonTag: jsonTag,
 	}
@@ -541,127 +351,137 @@ func extractFieldInfo(field reflect.StructField) FieldInfo {
 	// Parse GORM tags
 	fieldInfo.parseGormTags(gormTag)
 
+	// Like GORM, treat ID as the primary key and auto-increment integer primary keys
+	settings := schema.ParseTagSetting(gormTag, ";")
+	if field.Name == "ID" && !fieldInfo.PrimaryKey {
+		fieldInfo.PrimaryKey = true
+		fieldInfo.NotNull = true
+	}
+	if _, explicit := settings["AUTOINCREMENT"]; fieldInfo.PrimaryKey && !explicit && isIntegerType(field.Type) {
+		fieldInfo.AutoIncrement = true
+	}
+
+	fieldInfo.Type = getFieldType(field.Type, gormTag, fieldInfo.AutoIncrement)
+
 	return fieldInfo
 }
 
+// isIntegerType reports whether values of fieldType are stored as integers
+func isIntegerType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
 // getFieldName extracts the database field name
 func getFieldName(fieldName, gormTag string) string {
 	// Check if column name is specified in gorm tag
-	tags := strings.Split(gormTag, ";")
-	for _, tag := range tags {
-		if strings.HasPrefix(tag, "column:") {
-			return strings.TrimPrefix(tag, "column:")
-		}
+	if column := schema.ParseTagSetting(gormTag, ";")["COLUMN"]; column != "" {
+		return column
 	}
 
-	// Convert to snake_case
-	return toSnakeCase(fieldName)
+	// Convert to snake_case the way GORM names columns
+	return schema.NamingStrategy{}.ColumnName("", fieldName)
 }
 
-// getFieldType determines the database field type
-func getFieldType(fieldType reflect.Type, gormTag string) string {
+// getFieldType determines the database field type the way GORM's PostgreSQL
+// dialector does when it creates the column
+func getFieldType(fieldType reflect.Type, gormTag string, autoIncrement bool) string {
 	// Handle pointers
 	if fieldType.Kind() == reflect.Ptr {
 		fieldType = fieldType.Elem()
 	}
 
 	// Check for explicit type in gorm tag
-	tags := strings.Split(gormTag, ";")
-	for _, tag := range tags {
-		if strings.HasPrefix(tag, "type:") {
-			return strings.TrimPrefix(tag, "type:")
-		}
+	settings := schema.ParseTagSetting(gormTag, ";")
+	if explicitType := settings["TYPE"]; explicitType != "" {
+		return explicitType
 	}
 
-	// Check if this is an auto-increment field
-	isAutoIncrement := false
-	for _, tag := range tags {
-		if strings.TrimSpace(tag) == "autoIncrement" {
-			isAutoIncrement = true
-			break
+	// Time values, including nullable ones such as gorm.DeletedAt
+	if fieldType == reflect.TypeOf(time.Time{}) || fieldType.ConvertibleTo(reflect.TypeOf(sql.NullTime{})) {
+		if precision := settings["PRECISION"]; precision != "" {
+			return fmt.Sprintf("timestamptz(%s)", precision)
 		}
+		return "timestamptz"
 	}
 
 	// Map Go types to PostgreSQL types
 	switch fieldType.Kind() {
 	case reflect.String:
 		// Check for size specification
-		for _, tag := range tags {
-			if strings.HasPrefix(tag, "size:") {
-				size := strings.TrimPrefix(tag, "size:")
-				return fmt.Sprintf("varchar(%s)", size)
-			}
+		if size := settings["SIZE"]; size != "" {
+			return fmt.Sprintf("varchar(%s)", size)
 		}
 		return "text"
-	case reflect.Int, reflect.Int32:
-		if isAutoIncrement {
-			return "serial"
-		}
-		return "integer"
-	case reflect.Int64:
-		if isAutoIncrement {
-			return "bigserial"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// Unsigned values need one more bit to fit in a signed column
+		size := fieldType.Bits()
+		if fieldType.Kind() >= reflect.Uint {
+			size++
 		}
-		return "bigint"
-	case reflect.Uint:
-		// uint in Go should map to bigint in PostgreSQL to handle large values
-		if isAutoIncrement {
-			return "bigserial"
-		}
-		return "bigint"
-	case reflect.Uint32:
-		if isAutoIncrement {
+		switch {
+		case size <= 16 && autoIncrement:
+			return "smallserial"
+		case size <= 16:
+			return "smallint"
+		case size <= 32 && autoIncrement:
+			return "serial"
+		case size <= 32:
+			return "integer"
+		case autoIncrement:
 			return "bigserial"
 		}
 		return "bigint"
-	case reflect.Uint64:
-		if isAutoIncrement {
-			return "bigserial"
+	case reflect.Float32, reflect.Float64:
+		if precision := settings["PRECISION"]; precision != "" {
+			if scale := settings["SCALE"]; scale != "" {
+				return fmt.Sprintf("numeric(%s,%s)", precision, scale)
+			}
+			return fmt.Sprintf("numeric(%s)", precision)
 		}
-		return "bigint"
-	case reflect.Float32:
-		return "real"
-	case reflect.Float64:
-		return "double precision"
+		return "decimal"
 	case reflect.Bool:
 		return "boolean"
-	default:
-		if fieldType == reflect.TypeOf(time.Time{}) {
-			return "timestamp"
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			return "bytea"
 		}
-		return "text"
 	}
+	return "text"
 }
 
-// parseGormTags parses GORM tags and sets field properties
+// parseGormTags parses GORM tags and sets field properties. Tag names are case-insensitive as in GORM.
 func (fi *FieldInfo) parseGormTags(gormTag string) {
-	tags := strings.Split(gormTag, ";")
-
-	for _, tag := range tags {
-		tag = strings.TrimSpace(tag)
-
-		switch {
-		case tag == "primaryKey":
+	for key, value := range schema.ParseTagSetting(gormTag, ";") {
+		switch key {
+		case "PRIMARYKEY", "PRIMARY_KEY":
 			fi.PrimaryKey = true
 			fi.NotNull = true // Primary keys are always NOT NULL
-		case tag == "autoIncrement":
-			fi.AutoIncrement = true
-		case tag == "not null":
+		case "AUTOINCREMENT":
+			fi.AutoIncrement = !strings.EqualFold(value, "false")
+		case "NOT NULL", "NOTNULL":
 			fi.NotNull = true
-		case tag == "unique":
+		case "UNIQUE":
 			fi.Unique = true
-		case tag == "index":
+		case "INDEX", "UNIQUEINDEX":
 			fi.Index = true
-		case strings.HasPrefix(tag, "size:"):
-			fmt.Sscanf(tag, "size:%d", &fi.Size)
-		case strings.HasPrefix(tag, "default:"):
-			fi.Default = strings.TrimPrefix(tag, "default:")
-		case strings.HasPrefix(tag, "foreignKey:"):
-			fi.ForeignKey = strings.TrimPrefix(tag, "foreignKey:")
-		case strings.HasPrefix(tag, "constraint:"):
+		case "SIZE":
+			fmt.Sscanf(value, "%d", &fi.Size)
+		case "DEFAULT":
+			fi.Default = value
+		case "FOREIGNKEY":
+			fi.ForeignKey = value
+		case "CONSTRAINT":
 			// Parse constraint details: constraint:OnUpdate:CASCADE,OnDelete:SET NULL
-			constraintStr := strings.TrimPrefix(tag, "constraint:")
-			fi.parseConstraintDetails(constraintStr)
+			fi.parseConstraintDetails(value)
 		}
 	}
 }
@@ -681,7 +501,23 @@ func (fi *FieldInfo) parseConstraintDetails(constraintStr string) {
 	}
 }
 
-// DetectChanges detects schema changes by comparing current models with database schema
+// operationOrder is the order in which ExecuteMigrations must run operation types:
+// constraints and indexes are dropped before columns change and created after
+var operationOrder = map[string]int{
+	"drop_constraint": 0,
+	"drop_index":      1,
+	"create_table":    2,
+	"add_column":      3,
+	"modify_column":   4,
+	"drop_column":     5,
+	"add_index":       6,
+	"add_constraint":  7,
+}
+
+// DetectChanges detects schema changes by comparing current models with database schema.
+// Columns are compared by type, nullability and default, indexes by their columns and
+// uniqueness, and foreign keys by their referenced column and actions. Non-unique
+// indexes and constraints that no model declares are left alone.
 func (dm *DynamicMigrator) DetectChanges() ([]MigrationOperation, error) {
 	var operations []MigrationOperation
 
@@ -691,62 +527,487 @@ func (dm *DynamicMigrator) DetectChanges() ([]MigrationOperation, error) {
 
 		if !tableExists {
 			// Table doesn't exist, create it
+			createSQL, err := dm.createTableSQL(modelInfo.Model)
+			if err != nil {
+				return nil, fmt.Errorf("failed to plan table %s: %w", modelInfo.TableName, err)
+			}
 			operation := MigrationOperation{
 				Type:        "create_table",
 				TableName:   modelInfo.TableName,
+				SQL:         createSQL,
 				Description: fmt.Sprintf("Create table %s", modelInfo.TableName),
 			}
 			operations = append(operations, operation)
 			continue
 		}
 
-		// Table exists, check for column differences
-		for _, field := range modelInfo.Fields {
-			columnExists := dm.db.Migrator().HasColumn(modelInfo.TableName, field.Name)
-
-			if !columnExists {
-				// Column doesn't exist, add it
-				operation := MigrationOperation{
-					Type:        "add_column",
-					TableName:   modelInfo.TableName,
-					ColumnName:  field.Name,
-					NewField:    &field,
-					Description: fmt.Sprintf("Add column %s to table %s", field.Name, modelInfo.TableName),
-				}
-				operations = append(operations, operation)
-			}
-			// TODO: Add logic to detect column modifications
+		// Table exists, check for column, index and constraint differences
+		tableOperations, err := dm.detectTableChanges(modelInfo)
+		if err != nil {
+			return nil, err
 		}
+		operations = append(operations, tableOperations...)
 	}
 
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operationOrder[operations[i].Type] < operationOrder[operations[j].Type]
+	})
 	return operations, nil
 }
 
-// ExecuteMigrations executes the detected migration operations
-func (dm *DynamicMigrator) ExecuteMigrations(operations []MigrationOperation) error {
+// createTableSQL returns the statements AutoMigrate runs to create the table of a
+// model, captured from a dry run that does not touch the database
+func (dm *DynamicMigrator) createTableSQL(model interface{}) (string, error) {
+	capture := &sqlCapture{}
+	if err := dm.db.Session(&gorm.Session{DryRun: true, Logger: capture}).Migrator().CreateTable(model); err != nil {
+		return "", err
+	}
+	return strings.Join(capture.statements, ";\n"), nil
+}
+
+// sqlCapture is a GORM logger recording the statements of a dry run
+type sqlCapture struct {
+	statements []string
+}
+
+func (c *sqlCapture) LogMode(logger.LogLevel) logger.Interface      { return c }
+func (c *sqlCapture) Info(context.Context, string, ...interface{})  {}
+func (c *sqlCapture) Warn(context.Context, string, ...interface{})  {}
+func (c *sqlCapture) Error(context.Context, string, ...interface{}) {}
+func (c *sqlCapture) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	statement, _ := fc()
+	c.statements = append(c.statements, statement)
+}
+
+// IsDestructive reports whether the operation removes a column, index or constraint or
+// changes a column type, any of which can lose data or fail on existing rows
+func (op MigrationOperation) IsDestructive() bool {
+	switch op.Type {
+	case "drop_column", "drop_index", "drop_constraint":
+		return true
+	case "modify_column":
+		return op.OldField != nil && op.NewField != nil &&
+			normalizeColumnType(op.OldField.Type) != normalizeColumnType(op.NewField.Type)
+	}
+	return false
+}
+
+// detectTableChanges compares a model with its existing table
+func (dm *DynamicMigrator) detectTableChanges(modelInfo ModelInfo) ([]MigrationOperation, error) {
+	columns, err := inspectColumns(dm.db, modelInfo.TableName)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := inspectIndexes(dm.db, modelInfo.TableName)
+	if err != nil {
+		return nil, err
+	}
+	foreignKeys, err := inspectForeignKeys(dm.db, modelInfo.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	operations := columnOperations(modelInfo, columns)
+	operations = append(operations, indexOperations(modelInfo, indexes, true)...)
+	operations = append(operations, foreignKeyOperations(modelInfo, foreignKeys, true)...)
+	return operations, nil
+}
+
+// ReconcileIndexes creates the indexes and foreign keys the models declare that are
+// missing and recreates the ones whose definition changed. Undeclared indexes and
+// constraints are left alone, so it is safe to run on every start.
+func (dm *DynamicMigrator) ReconcileIndexes() error {
+	var operations []MigrationOperation
+	for _, modelInfo := range dm.models {
+		if !dm.db.Migrator().HasTable(modelInfo.TableName) {
+			continue
+		}
+		indexes, err := inspectIndexes(dm.db, modelInfo.TableName)
+		if err != nil {
+			return err
+		}
+		foreignKeys, err := inspectForeignKeys(dm.db, modelInfo.TableName)
+		if err != nil {
+			return err
+		}
+		operations = append(operations, indexOperations(modelInfo, indexes, false)...)
+		operations = append(operations, foreignKeyOperations(modelInfo, foreignKeys, false)...)
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operationOrder[operations[i].Type] < operationOrder[operations[j].Type]
+	})
 	for _, operation := range operations {
-		switch operation.Type {
-		case "create_table":
-			// Find the model for this table
-			var model interface{}
-			for _, modelInfo := range dm.models {
-				if modelInfo.TableName == operation.TableName {
-					model = modelInfo.Model
-					break
-				}
+		helpers.Info("%s", operation.Description)
+	}
+	return dm.ExecuteMigrations(operations)
+}
+
+// columnOperations adds missing columns, modifies changed ones and drops the ones no field maps to
+func columnOperations(modelInfo ModelInfo, columns []ColumnInfo) []MigrationOperation {
+	var operations []MigrationOperation
+	table := modelInfo.TableName
+
+	existing := make(map[string]ColumnInfo, len(columns))
+	for _, column := range columns {
+		existing[column.Name] = column
+	}
+
+	modelColumns := make(map[string]bool, len(modelInfo.Fields))
+	for i := range modelInfo.Fields {
+		field := &modelInfo.Fields[i]
+		modelColumns[field.Name] = true
+
+		column, ok := existing[field.Name]
+		if !ok {
+			// Column doesn't exist, add it
+			operations = append(operations, MigrationOperation{
+				Type:        "add_column",
+				TableName:   table,
+				ColumnName:  field.Name,
+				NewField:    field,
+				SQL:         fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", quoteIdentifier(table), columnDefinition(field)),
+				Description: fmt.Sprintf("Add column %s to table %s", field.Name, table),
+			})
+			continue
+		}
+
+		oldField := &FieldInfo{
+			Name:       column.Name,
+			Type:       column.Type,
+			NotNull:    !column.IsNullable,
+			Default:    column.Default,
+			PrimaryKey: column.IsPrimaryKey,
+		}
+		if clauses, changes := alterColumnClauses(oldField, field); len(clauses) > 0 {
+			operations = append(operations, MigrationOperation{
+				Type:        "modify_column",
+				TableName:   table,
+				ColumnName:  field.Name,
+				OldField:    oldField,
+				NewField:    field,
+				SQL:         fmt.Sprintf("ALTER TABLE %s %s", quoteIdentifier(table), strings.Join(clauses, ", ")),
+				Description: fmt.Sprintf("Modify column %s of table %s (%s)", field.Name, table, strings.Join(changes, ", ")),
+			})
+		}
+	}
+
+	for _, column := range columns {
+		if modelColumns[column.Name] {
+			continue
+		}
+		operations = append(operations, MigrationOperation{
+			Type:       "drop_column",
+			TableName:  table,
+			ColumnName: column.Name,
+			OldField: &FieldInfo{
+				Name:    column.Name,
+				Type:    column.Type,
+				NotNull: !column.IsNullable,
+				Default: column.Default,
+			},
+			SQL:         fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteIdentifier(table), quoteIdentifier(column.Name)),
+			Description: fmt.Sprintf("Drop column %s from table %s", column.Name, table),
+		})
+	}
+	return operations
+}
+
+// columnDefinition returns the column name, type, nullability and default of a field
+func columnDefinition(field *FieldInfo) string {
+	definition := quoteIdentifier(field.Name) + " " + field.Type
+	if field.NotNull {
+		definition += " NOT NULL"
+	}
+	if defaultValue := normalizeDefault(field.Default, field.Type); defaultValue != "" {
+		definition += " DEFAULT " + defaultValue
+	}
+	return definition
+}
+
+// alterColumnClauses returns the ALTER COLUMN clauses turning oldField into newField
+// and a short description of each change
+func alterColumnClauses(oldField, newField *FieldInfo) (clauses, changes []string) {
+	column := quoteIdentifier(newField.Name)
+
+	oldType, newType := normalizeColumnType(oldField.Type), normalizeColumnType(newField.Type)
+	if oldType != newType {
+		clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s::%s", column, newType, column, newType))
+		changes = append(changes, fmt.Sprintf("type %s to %s", oldType, newType))
+	}
+
+	if oldField.NotNull != newField.NotNull {
+		if newField.NotNull {
+			clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", column))
+			changes = append(changes, "set not null")
+		} else {
+			clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", column))
+			changes = append(changes, "drop not null")
+		}
+	}
+
+	// Auto-increment columns get their default from a sequence
+	if !newField.AutoIncrement {
+		oldDefault, newDefault := normalizeDefault(oldField.Default, oldType), normalizeDefault(newField.Default, newType)
+		if oldDefault != newDefault {
+			if newDefault == "" {
+				clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", column))
+				changes = append(changes, "drop default")
+			} else {
+				clauses = append(clauses, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", column, newDefault))
+				changes = append(changes, "default "+newDefault)
+			}
+		}
+	}
+	return clauses, changes
+}
+
+// declaredIndexes returns the indexes a model declares, named as GORM names them:
+// unique constraints from unique tags, indexes from index and uniqueIndex tags, where
+// fields sharing an index name form a composite index, and the indexes returned by
+// the Indexes method of models implementing models.Indexer
+func declaredIndexes(modelSchema *schema.Schema, model interface{}, fields []FieldInfo) []IndexInfo {
+	var indexes []IndexInfo
+
+	for _, field := range fields {
+		if field.Unique && !field.PrimaryKey {
+			indexes = append(indexes, IndexInfo{
+				Name:       fmt.Sprintf("uni_%s_%s", modelSchema.Table, field.Name),
+				Columns:    []string{field.Name},
+				Unique:     true,
+				Constraint: true,
+			})
+		}
+	}
+
+	for _, index := range modelSchema.ParseIndexes() {
+		info := IndexInfo{Name: index.Name, Unique: index.Class == "UNIQUE", Where: index.Where}
+		for _, option := range index.Fields {
+			if option.Expression != "" {
+				info.Columns = append(info.Columns, option.Expression)
+				info.Expression = true
+				continue
+			}
+			info.Columns = append(info.Columns, option.DBName)
+		}
+		indexes = append(indexes, info)
+	}
+
+	if indexer, ok := model.(models.Indexer); ok {
+		for _, index := range indexer.Indexes() {
+			name := index.Name
+			if name == "" {
+				name = fmt.Sprintf("idx_%s_%s", modelSchema.Table, strings.Join(index.Columns, "_"))
 			}
+			indexes = append(indexes, IndexInfo{
+				Name:    name,
+				Columns: index.Columns,
+				Unique:  index.Unique,
+				Where:   index.Where,
+			})
+		}
+	}
+	return indexes
+}
 
-			if model != nil {
-				if err := dm.db.AutoMigrate(model); err != nil {
-					return fmt.Errorf("failed to create table %s: %w", operation.TableName, err)
-				}
+// indexOperations creates the declared indexes that are missing and recreates the ones
+// whose definition changed. With prune it also drops unique indexes and constraints that
+// are no longer declared.
+func indexOperations(modelInfo ModelInfo, existing []IndexInfo, prune bool) []MigrationOperation {
+	var operations []MigrationOperation
+	table := modelInfo.TableName
+	expected := modelInfo.Indexes
+	dropped := make(map[string]bool)
+
+	dropIndex := func(index IndexInfo) {
+		if dropped[index.Name] {
+			return
+		}
+		dropped[index.Name] = true
+		if index.Constraint {
+			operations = append(operations, MigrationOperation{
+				Type:           "drop_constraint",
+				TableName:      table,
+				ConstraintName: index.Name,
+				IndexColumns:   index.Columns,
+				Unique:         index.Unique,
+				SQL:            fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", quoteIdentifier(table), quoteIdentifier(index.Name)),
+				Description:    fmt.Sprintf("Drop constraint %s from table %s", index.Name, table),
+			})
+			return
+		}
+		operations = append(operations, MigrationOperation{
+			Type:         "drop_index",
+			TableName:    table,
+			IndexName:    index.Name,
+			IndexColumns: index.Columns,
+			Unique:       index.Unique,
+			SQL:          fmt.Sprintf("DROP INDEX IF EXISTS %s", quoteIdentifier(index.Name)),
+			Description:  fmt.Sprintf("Drop index %s from table %s", index.Name, table),
+		})
+	}
+
+	// Partial and expression indexes must also keep their name, as their predicates and
+	// expressions are not compared
+	matches := func(want, index IndexInfo) bool {
+		if want.Expression || index.Expression {
+			return want.Expression && index.Expression && want.Name == index.Name
+		}
+		if want.Where != "" || index.Where != "" {
+			if want.Where == "" || index.Where == "" || want.Name != index.Name {
+				return false
+			}
+		}
+		return !index.Primary && want.Unique == index.Unique && slices.Equal(want.Columns, index.Columns)
+	}
+
+	// Unique indexes that no declared unique index matches
+	for _, index := range existing {
+		if prune && index.Unique && !index.Primary && index.Where == "" && !index.Expression &&
+			!slices.ContainsFunc(expected, func(want IndexInfo) bool { return matches(want, index) }) {
+			dropIndex(index)
+		}
+	}
+
+	for _, want := range expected {
+		if slices.ContainsFunc(existing, func(index IndexInfo) bool { return matches(want, index) }) {
+			continue
+		}
+
+		// An index with the same name but another definition is replaced
+		if i := slices.IndexFunc(existing, func(index IndexInfo) bool { return index.Name == want.Name }); i >= 0 {
+			dropIndex(existing[i])
+		}
+
+		unique := ""
+		if want.Unique {
+			unique = "UNIQUE "
+		}
+		columns := make([]string, len(want.Columns))
+		for i, column := range want.Columns {
+			columns[i] = column
+			if identifierPattern.MatchString(column) {
+				columns[i] = quoteIdentifier(column)
+			}
+		}
+		where, description := "", ""
+		if want.Where != "" {
+			where = " WHERE " + want.Where
+			description = " where " + want.Where
+		}
+		operations = append(operations, MigrationOperation{
+			Type:         "add_index",
+			TableName:    table,
+			IndexName:    want.Name,
+			IndexColumns: want.Columns,
+			Unique:       want.Unique,
+			SQL: fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s)%s",
+				unique, quoteIdentifier(want.Name), quoteIdentifier(table), strings.Join(columns, ", "), where),
+			Description: fmt.Sprintf("Create %sindex %s on table %s (%s)%s",
+				strings.ToLower(unique), want.Name, table, strings.Join(want.Columns, ", "), description),
+		})
+	}
+	return operations
+}
+
+// foreignKeyOperations creates the foreign keys of association fields that are missing and
+// recreates changed ones. With prune it also drops foreign keys on columns no association
+// references.
+func foreignKeyOperations(modelInfo ModelInfo, existing []ForeignKeyInfo, prune bool) []MigrationOperation {
+	var operations []MigrationOperation
+	table := modelInfo.TableName
+
+	byColumn := make(map[string]ForeignKeyInfo, len(existing))
+	for _, foreignKey := range existing {
+		byColumn[foreignKey.ColumnName] = foreignKey
+	}
+
+	dropConstraint := func(foreignKey ForeignKeyInfo) {
+		operations = append(operations, MigrationOperation{
+			Type:             "drop_constraint",
+			TableName:        table,
+			ColumnName:       foreignKey.ColumnName,
+			ConstraintName:   foreignKey.Name,
+			ReferencedTable:  foreignKey.ReferencedTable,
+			ReferencedColumn: foreignKey.ReferencedColumn,
+			OnUpdate:         foreignKey.OnUpdate,
+			OnDelete:         foreignKey.OnDelete,
+			SQL:              fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", quoteIdentifier(table), quoteIdentifier(foreignKey.Name)),
+			Description:      fmt.Sprintf("Drop foreign key %s from table %s", foreignKey.Name, table),
+		})
+	}
+
+	referenced := make(map[string]bool)
+	for _, field := range modelInfo.Fields {
+		if field.ReferencedTable == "" {
+			continue
+		}
+		referenced[field.Name] = true
+
+		want := ForeignKeyInfo{
+			Name:             fmt.Sprintf("fk_%s_%s", table, field.Name),
+			ColumnName:       field.Name,
+			ReferencedTable:  field.ReferencedTable,
+			ReferencedColumn: field.ReferencedColumn,
+			OnUpdate:         foreignKeyAction(field.OnUpdate),
+			OnDelete:         foreignKeyAction(field.OnDelete),
+		}
+		if current, ok := byColumn[field.Name]; ok {
+			if current.ReferencedTable == want.ReferencedTable && current.ReferencedColumn == want.ReferencedColumn &&
+				current.OnUpdate == want.OnUpdate && current.OnDelete == want.OnDelete {
+				continue
 			}
+			dropConstraint(current)
+			want.Name = current.Name
+		}
+
+		operations = append(operations, MigrationOperation{
+			Type:             "add_constraint",
+			TableName:        table,
+			ColumnName:       field.Name,
+			ConstraintName:   want.Name,
+			ReferencedTable:  want.ReferencedTable,
+			ReferencedColumn: want.ReferencedColumn,
+			OnUpdate:         want.OnUpdate,
+			OnDelete:         want.OnDelete,
+			SQL: fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s) ON UPDATE %s ON DELETE %s",
+				quoteIdentifier(table), quoteIdentifier(want.Name), quoteIdentifier(field.Name),
+				quoteIdentifier(want.ReferencedTable), quoteIdentifier(want.ReferencedColumn), want.OnUpdate, want.OnDelete),
+			Description: fmt.Sprintf("Add foreign key %s on %s.%s referencing %s.%s",
+				want.Name, table, field.Name, want.ReferencedTable, want.ReferencedColumn),
+		})
+	}
+
+	for _, foreignKey := range existing {
+		if prune && !referenced[foreignKey.ColumnName] {
+			dropConstraint(foreignKey)
+		}
+	}
+	return operations
+}
+
+// foreignKeyAction spells an OnUpdate or OnDelete tag value as SQL, defaulting to NO ACTION
+func foreignKeyAction(action string) string {
+	action = strings.ToUpper(strings.Join(strings.Fields(action), " "))
+	for _, known := range foreignKeyActions {
+		if action == known {
+			return action
+		}
+	}
+	return "NO ACTION"
+}
 
-		case "add_column":
-			// Use GORM's migrator to add column
-			if operation.NewField != nil {
-				// Create a temporary struct to represent the new column
-				// For now, we'll use AutoMigrate to handle this
+// ExecuteMigrations executes the detected migration operations in one transaction.
+// drop_column operations are skipped unless AllowDropColumns enabled them.
+func (dm *DynamicMigrator) ExecuteMigrations(operations []MigrationOperation) error {
+	return dm.db.Transaction(func(tx *gorm.DB) error {
+		for _, operation := range operations {
+			switch operation.Type {
+			case "create_table":
+				// Find the model for this table
 				var model interface{}
 				for _, modelInfo := range dm.models {
 					if modelInfo.TableName == operation.TableName {
@@ -756,25 +1017,30 @@ func (dm *DynamicMigrator) ExecuteMigrations(operations []MigrationOperation) er
 				}
 
 				if model != nil {
-					if err := dm.db.AutoMigrate(model); err != nil {
-						return fmt.Errorf("failed to add column %s to table %s: %w",
-							operation.ColumnName, operation.TableName, err)
+					if err := tx.AutoMigrate(model); err != nil {
+						return fmt.Errorf("failed to create table %s: %w", operation.TableName, err)
 					}
 				}
-			}
 
-		case "modify_column":
-			// Handle column modifications
-			// TODO: Implement column modification logic
+			case "drop_column":
+				if !dm.allowDropColumns {
+					helpers.Warning("Skipped: %s (dropping columns is not allowed)", operation.Description)
+					continue
+				}
+				if err := tx.Exec(operation.SQL).Error; err != nil {
+					return fmt.Errorf("%s: %w", operation.Description, err)
+				}
 
-		case "add_index":
-			// Handle index creation
-			// TODO: Implement index creation logic
+			case "add_column", "modify_column", "add_index", "drop_index", "add_constraint", "drop_constraint":
+				if err := tx.Exec(operation.SQL).Error; err != nil {
+					return fmt.Errorf("%s: %w", operation.Description, err)
+				}
 
-		default:
-			return fmt.Errorf("unsupported migration operation: %s", operation.Type)
+			default:
+				return fmt.Errorf("unsupported migration operation: %s", operation.Type)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
@@ -0,0 +1,2 @@
+-- The cleared phone numbers were empty, so there is nothing to restore, and the
+-- column stays nullable as the User model requires.
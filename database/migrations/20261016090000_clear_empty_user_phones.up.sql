@@ -0,0 +1,4 @@
+-- Users registered before phone numbers were captured were stored with an empty
+-- phone, so every registration after the first collided on the unique index.
+ALTER TABLE users ALTER COLUMN phone DROP NOT NULL;
+UPDATE users SET phone = NULL WHERE TRIM(phone) = '';
@@ -0,0 +1 @@
+-- The original spelling of the emails is not kept, so there is nothing to restore.
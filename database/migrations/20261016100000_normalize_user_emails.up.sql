@@ -0,0 +1,8 @@
+-- Emails used to be stored as typed. Lowercase them so logins in any case find the
+-- account. Addresses that differ only in case are left for an admin to merge.
+UPDATE users SET email = LOWER(TRIM(email))
+WHERE email <> LOWER(TRIM(email))
+	AND NOT EXISTS (
+		SELECT 1 FROM users other
+		WHERE other.id <> users.id AND LOWER(TRIM(other.email)) = LOWER(TRIM(users.email))
+	);
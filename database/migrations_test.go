@@ -0,0 +1,250 @@
+package database
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"go-fiber-template/models"
+	"go-fiber-template/testutil"
+)
+
+// indexedModel declares the index kinds that have no registered model
+type indexedModel struct {
+	ID    uint   `gorm:"primarykey"`
+	Code  string `gorm:"unique"`
+	Email string `gorm:"index:idx_indexed_models_lower_email,expression:lower(email)"`
+}
+
+func TestDeclaredIndexes(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+		want  []IndexInfo
+	}{
+		{
+			name:  "tag and partial indexes",
+			model: &models.Invitation{},
+			want: []IndexInfo{
+				{Name: "idx_invitations_email", Columns: []string{"email"}},
+				{Name: "idx_invitations_token_hash", Columns: []string{"token_hash"}, Unique: true},
+				{Name: "idx_invitations_invited_by_id", Columns: []string{"invited_by_id"}},
+				{Name: "idx_invitations_pending_email", Columns: []string{"email"}, Where: "accepted_at IS NULL AND revoked_at IS NULL"},
+			},
+		},
+		{
+			name:  "composite unique and single column indexes",
+			model: &models.RateLimitCounter{},
+			want: []IndexInfo{
+				{Name: "idx_rate_limit_counters_key_window", Columns: []string{"key", "window_start"}, Unique: true},
+				{Name: "idx_rate_limit_counters_expires_at", Columns: []string{"expires_at"}},
+			},
+		},
+		{
+			name:  "unique constraint and expression index",
+			model: &indexedModel{},
+			want: []IndexInfo{
+				{Name: "uni_indexed_models_code", Columns: []string{"code"}, Unique: true, Constraint: true},
+				{Name: "idx_indexed_models_lower_email", Columns: []string{"lower(email)"}, Expression: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelSchema, err := parseModel(testutil.NewDB(t, nil).DB, tt.model)
+			if err != nil {
+				t.Fatalf("parseModel() error = %v", err)
+			}
+			if got := extractModelInfo(modelSchema, tt.model).Indexes; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("declared indexes =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexOperations(t *testing.T) {
+	emailIndex := IndexInfo{Name: "idx_users_email", Columns: []string{"email"}, Unique: true}
+	pendingIndex := IndexInfo{Name: "idx_users_pending", Columns: []string{"email"}, Where: "deleted_at IS NULL"}
+	lowerIndex := IndexInfo{Name: "idx_users_lower_email", Columns: []string{"lower(email)"}, Expression: true}
+	primaryKey := IndexInfo{Name: "users_pkey", Columns: []string{"id"}, Unique: true, Primary: true, Constraint: true}
+
+	tests := []struct {
+		name     string
+		expected []IndexInfo
+		existing []IndexInfo
+		prune    bool
+		want     []string
+	}{
+		{
+			name:     "missing index is created",
+			expected: []IndexInfo{emailIndex},
+			existing: []IndexInfo{primaryKey},
+			want:     []string{`CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email")`},
+		},
+		{
+			name:     "unchanged index",
+			expected: []IndexInfo{emailIndex},
+			existing: []IndexInfo{primaryKey, emailIndex},
+		},
+		{
+			name:     "existing index under another name",
+			expected: []IndexInfo{emailIndex},
+			existing: []IndexInfo{{Name: "users_email_key", Columns: []string{"email"}, Unique: true, Constraint: true}},
+		},
+		{
+			name:     "changed uniqueness is recreated",
+			expected: []IndexInfo{emailIndex},
+			existing: []IndexInfo{{Name: "idx_users_email", Columns: []string{"email"}}},
+			want: []string{
+				`DROP INDEX IF EXISTS "idx_users_email"`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email")`,
+			},
+		},
+		{
+			name:     "changed columns are recreated",
+			expected: []IndexInfo{{Name: "idx_users_name", Columns: []string{"last_name", "first_name"}}},
+			existing: []IndexInfo{{Name: "idx_users_name", Columns: []string{"first_name", "last_name"}}},
+			want: []string{
+				`DROP INDEX IF EXISTS "idx_users_name"`,
+				`CREATE INDEX IF NOT EXISTS "idx_users_name" ON "users" ("last_name", "first_name")`,
+			},
+		},
+		{
+			name:     "primary key does not satisfy an index",
+			expected: []IndexInfo{{Name: "idx_users_id", Columns: []string{"id"}, Unique: true}},
+			existing: []IndexInfo{primaryKey},
+			want:     []string{`CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_id" ON "users" ("id")`},
+		},
+		{
+			name:     "partial index is matched by name",
+			expected: []IndexInfo{pendingIndex},
+			existing: []IndexInfo{{Name: "idx_users_pending", Columns: []string{"email"}, Where: "(deleted_at IS NULL)"}},
+		},
+		{
+			name:     "plain index does not satisfy a partial index",
+			expected: []IndexInfo{pendingIndex},
+			existing: []IndexInfo{{Name: "idx_users_email", Columns: []string{"email"}}},
+			want:     []string{`CREATE INDEX IF NOT EXISTS "idx_users_pending" ON "users" ("email") WHERE deleted_at IS NULL`},
+		},
+		{
+			name:     "expression index is matched by name",
+			expected: []IndexInfo{lowerIndex},
+			existing: []IndexInfo{{Name: "idx_users_lower_email", Columns: []string{"lower((email)::text)"}, Expression: true}},
+		},
+		{
+			name:     "expression is not quoted",
+			expected: []IndexInfo{lowerIndex},
+			want:     []string{`CREATE INDEX IF NOT EXISTS "idx_users_lower_email" ON "users" (lower(email))`},
+		},
+		{
+			name:     "undeclared unique index is kept without prune",
+			existing: []IndexInfo{primaryKey, emailIndex},
+		},
+		{
+			name:     "undeclared unique index and constraint are dropped with prune",
+			existing: []IndexInfo{primaryKey, emailIndex, {Name: "uni_users_code", Columns: []string{"code"}, Unique: true, Constraint: true}},
+			prune:    true,
+			want: []string{
+				`DROP INDEX IF EXISTS "idx_users_email"`,
+				`ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "uni_users_code"`,
+			},
+		},
+		{
+			name:     "prune keeps plain, partial and expression indexes",
+			existing: []IndexInfo{{Name: "idx_users_name", Columns: []string{"name"}}, pendingIndex, {Name: "idx_users_unique_lower", Columns: []string{"lower(email)"}, Unique: true, Expression: true}},
+			prune:    true,
+		},
+		{
+			name:     "pruned index replaced under the same name is dropped once",
+			expected: []IndexInfo{{Name: "idx_users_email", Columns: []string{"email", "tenant_id"}, Unique: true}},
+			existing: []IndexInfo{emailIndex},
+			prune:    true,
+			want: []string{
+				`DROP INDEX IF EXISTS "idx_users_email"`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email", "tenant_id")`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operations := indexOperations(ModelInfo{TableName: "users", Indexes: tt.expected}, tt.existing, tt.prune)
+			var got []string
+			for _, operation := range operations {
+				got = append(got, operation.SQL)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("indexOperations() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForeignKeyOperations(t *testing.T) {
+	modelInfo := ModelInfo{
+		TableName: "invitations",
+		Fields: []FieldInfo{
+			{Name: "id", PrimaryKey: true},
+			{Name: "invited_by_id", ReferencedTable: "users", ReferencedColumn: "id", OnUpdate: "cascade", OnDelete: "SET  NULL"},
+		},
+	}
+	current := ForeignKeyInfo{
+		Name:             "fk_invitations_invited_by",
+		ColumnName:       "invited_by_id",
+		ReferencedTable:  "users",
+		ReferencedColumn: "id",
+		OnUpdate:         "CASCADE",
+		OnDelete:         "SET NULL",
+	}
+	stale := ForeignKeyInfo{Name: "fk_invitations_team", ColumnName: "team_id", ReferencedTable: "teams", ReferencedColumn: "id"}
+
+	changed := current
+	changed.OnDelete = "CASCADE"
+
+	tests := []struct {
+		name     string
+		existing []ForeignKeyInfo
+		prune    bool
+		want     []string
+	}{
+		{
+			name: "missing foreign key is added",
+			want: []string{`ALTER TABLE "invitations" ADD CONSTRAINT "fk_invitations_invited_by_id" FOREIGN KEY ("invited_by_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL`},
+		},
+		{
+			name:     "unchanged foreign key under another name",
+			existing: []ForeignKeyInfo{current},
+		},
+		{
+			name:     "changed action is recreated under the existing name",
+			existing: []ForeignKeyInfo{changed},
+			want: []string{
+				`ALTER TABLE "invitations" DROP CONSTRAINT IF EXISTS "fk_invitations_invited_by"`,
+				`ALTER TABLE "invitations" ADD CONSTRAINT "fk_invitations_invited_by" FOREIGN KEY ("invited_by_id") REFERENCES "users" ("id") ON UPDATE CASCADE ON DELETE SET NULL`,
+			},
+		},
+		{
+			name:     "unreferenced foreign key is kept without prune",
+			existing: []ForeignKeyInfo{current, stale},
+		},
+		{
+			name:     "unreferenced foreign key is dropped with prune",
+			existing: []ForeignKeyInfo{current, stale},
+			prune:    true,
+			want:     []string{`ALTER TABLE "invitations" DROP CONSTRAINT IF EXISTS "fk_invitations_team"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, operation := range foreignKeyOperations(modelInfo, tt.existing, tt.prune) {
+				got = append(got, operation.SQL)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("foreignKeyOperations() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// PlannedOperation is a schema change the dynamic migrator would make
+type PlannedOperation struct {
+	Type        string `json:"type"`
+	Table       string `json:"table"`
+	Column      string `json:"column,omitempty"`
+	Description string `json:"description"`
+	SQL         string `json:"sql"`
+	Destructive bool   `json:"destructive"`
+	// RequiresOptIn marks drop_column operations, which only run when dropping columns is allowed
+	RequiresOptIn bool `json:"requires_opt_in,omitempty"`
+}
+
+// PlannedMigration is a versioned migration that has not been applied
+type PlannedMigration struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	// SQL is empty for Go migrations
+	SQL string `json:"sql,omitempty"`
+}
+
+// MigrationPlan lists the pending schema changes without applying any of them
+type MigrationPlan struct {
+	Operations []PlannedOperation `json:"operations"`
+	Migrations []PlannedMigration `json:"migrations"`
+}
+
+// BuildMigrationPlan detects the changes between the registered models and the database
+// and the pending versioned migrations. It only reads from the database.
+func BuildMigrationPlan(ctx context.Context, db *gorm.DB) (*MigrationPlan, error) {
+	plan := &MigrationPlan{
+		Operations: []PlannedOperation{},
+		Migrations: []PlannedMigration{},
+	}
+
+	dynamicMigrator, err := NewDynamicMigrator(db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	operations, err := dynamicMigrator.DetectChanges()
+	if err != nil {
+		return nil, err
+	}
+	for _, operation := range operations {
+		plan.Operations = append(plan.Operations, PlannedOperation{
+			Type:          operation.Type,
+			Table:         operation.TableName,
+			Column:        operation.ColumnName,
+			Description:   operation.Description,
+			SQL:           operation.SQL,
+			Destructive:   operation.IsDestructive(),
+			RequiresOptIn: operation.Type == "drop_column",
+		})
+	}
+
+	migrator, err := NewVersionedMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range pending {
+		plan.Migrations = append(plan.Migrations, PlannedMigration{
+			Version: migration.Version,
+			Name:    migration.Name,
+			SQL:     migration.SQL,
+		})
+	}
+
+	return plan, nil
+}
+
+// HasChanges reports whether the plan has any operation or migration
+func (p *MigrationPlan) HasChanges() bool {
+	return len(p.Operations) > 0 || len(p.Migrations) > 0
+}
+
+// DestructiveCount returns the number of destructive operations
+func (p *MigrationPlan) DestructiveCount() int {
+	count := 0
+	for _, operation := range p.Operations {
+		if operation.Destructive {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-fiber-template/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// registeredModel is a registered model with its GORM schema
+type registeredModel struct {
+	models.RegisteredModel
+	Schema *schema.Schema
+}
+
+// orderedModels parses the registered models with GORM and orders them so that every
+// model comes after the models it depends on through foreign keys or DependsOn.
+// Independent models keep their registration order. A dependency cycle is an error.
+func orderedModels(db *gorm.DB) ([]registeredModel, error) {
+	return orderModels(db, models.Registered())
+}
+
+// orderModels parses and orders the given models like orderedModels
+func orderModels(db *gorm.DB, registered []models.RegisteredModel) ([]registeredModel, error) {
+	var entries []registeredModel
+	byTable := make(map[string]registeredModel)
+	for _, model := range registered {
+		modelSchema, err := parseModel(db, model.Model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse model %s: %w", model.Name, err)
+		}
+		if existing, ok := byTable[modelSchema.Table]; ok {
+			return nil, fmt.Errorf("models %s and %s share the table %s", existing.Name, model.Name, modelSchema.Table)
+		}
+		entry := registeredModel{RegisteredModel: model, Schema: modelSchema}
+		entries = append(entries, entry)
+		byTable[modelSchema.Table] = entry
+	}
+
+	dependencies := make(map[string][]string)
+	addDependency := func(table, dependency string) error {
+		if table == dependency {
+			return nil
+		}
+		if _, ok := byTable[dependency]; !ok {
+			return fmt.Errorf("model %s depends on table %s, which no registered model maps to", byTable[table].Name, dependency)
+		}
+		dependencies[table] = append(dependencies[table], dependency)
+		return nil
+	}
+
+	for _, entry := range entries {
+		// Foreign keys of associations on either side, e.g. a has-many on the referenced model
+		names := make([]string, 0, len(entry.Schema.Relationships.Relations))
+		for name := range entry.Schema.Relationships.Relations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			constraint := entry.Schema.Relationships.Relations[name].ParseConstraint()
+			if constraint == nil || constraint.Schema == nil || constraint.ReferenceSchema == nil {
+				continue
+			}
+			if _, ok := byTable[constraint.Schema.Table]; !ok {
+				continue
+			}
+			if err := addDependency(constraint.Schema.Table, constraint.ReferenceSchema.Table); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, dependency := range entry.DependsOn {
+			dependencySchema, err := parseModel(db, dependency)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse dependency %T of model %s: %w", dependency, entry.Name, err)
+			}
+			if err := addDependency(entry.Schema.Table, dependencySchema.Table); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Depth-first search adding each model after its dependencies
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	ordered := make([]registeredModel, 0, len(entries))
+
+	var visit func(table string) error
+	visit = func(table string) error {
+		switch state[table] {
+		case visited:
+			return nil
+		case visiting:
+			start := len(path) - 1
+			for path[start] != table {
+				start--
+			}
+			cycle := append(append([]string{}, path[start:]...), table)
+			return fmt.Errorf("models have a foreign key cycle: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[table] = visiting
+		path = append(path, table)
+		for _, dependency := range dependencies[table] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[table] = visited
+		ordered = append(ordered, byTable[table])
+		return nil
+	}
+
+	for _, entry := range entries {
+		if err := visit(entry.Schema.Table); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// parseModel returns the GORM schema of a model, with the naming strategy of db
+func parseModel(db *gorm.DB, model interface{}) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go-fiber-template/models"
+	"go-fiber-template/testutil"
+)
+
+type author struct {
+	ID    uint
+	Books []book
+}
+
+type book struct {
+	ID       uint
+	AuthorID uint
+}
+
+type review struct {
+	ID     uint
+	BookID uint
+	Book   book
+}
+
+type tag struct {
+	ID uint
+}
+
+type category struct {
+	ID       uint
+	ParentID *uint
+	Parent   *category
+}
+
+type team struct {
+	ID      uint
+	OwnerID uint
+	Owner   *member `gorm:"foreignKey:OwnerID"`
+}
+
+type member struct {
+	ID     uint
+	TeamID uint
+	Team   *team `gorm:"foreignKey:TeamID"`
+}
+
+type bookCopy struct {
+	ID uint
+}
+
+func (bookCopy) TableName() string { return "books" }
+
+func TestOrderModels(t *testing.T) {
+	model := func(name string, model interface{}, dependsOn ...interface{}) models.RegisteredModel {
+		return models.RegisteredModel{Name: name, Model: model, DependsOn: dependsOn}
+	}
+
+	tests := []struct {
+		name       string
+		registered []models.RegisteredModel
+		want       []string
+		wantErr    string
+	}{
+		{
+			name:       "independent models keep their registration order",
+			registered: []models.RegisteredModel{model("tag", &tag{}), model("author", &author{}), model("category", &category{})},
+			want:       []string{"tag", "author", "category"},
+		},
+		{
+			name:       "belongs-to comes after the referenced model",
+			registered: []models.RegisteredModel{model("review", &review{}), model("book", &book{})},
+			want:       []string{"book", "review"},
+		},
+		{
+			name:       "has-many declared on the referenced model",
+			registered: []models.RegisteredModel{model("book", &book{}), model("author", &author{})},
+			want:       []string{"author", "book"},
+		},
+		{
+			name:       "chain of dependencies",
+			registered: []models.RegisteredModel{model("review", &review{}), model("tag", &tag{}), model("book", &book{}), model("author", &author{})},
+			want:       []string{"author", "book", "review", "tag"},
+		},
+		{
+			name:       "DependsOn",
+			registered: []models.RegisteredModel{model("tag", &tag{}, &category{}), model("category", &category{})},
+			want:       []string{"category", "tag"},
+		},
+		{
+			name:       "self reference",
+			registered: []models.RegisteredModel{model("category", &category{})},
+			want:       []string{"category"},
+		},
+		{
+			name:       "foreign key cycle",
+			registered: []models.RegisteredModel{model("tag", &tag{}), model("team", &team{}), model("member", &member{})},
+			wantErr:    "models have a foreign key cycle: teams -> members -> teams",
+		},
+		{
+			name:       "DependsOn cycle",
+			registered: []models.RegisteredModel{model("tag", &tag{}, &category{}), model("category", &category{}, &tag{})},
+			wantErr:    "models have a foreign key cycle: tags -> categories -> tags",
+		},
+		{
+			name:       "foreign key to an unregistered model",
+			registered: []models.RegisteredModel{model("review", &review{})},
+			wantErr:    "model review depends on table books, which no registered model maps to",
+		},
+		{
+			name:       "DependsOn an unregistered model",
+			registered: []models.RegisteredModel{model("tag", &tag{}, &category{})},
+			wantErr:    "model tag depends on table categories, which no registered model maps to",
+		},
+		{
+			name:       "shared table",
+			registered: []models.RegisteredModel{model("book", &book{}), model("bookCopy", &bookCopy{})},
+			wantErr:    "models book and bookCopy share the table books",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := orderModels(testutil.NewDB(t, nil).DB, tt.registered)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("orderModels() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("orderModels() error = %v", err)
+			}
+
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("orderModels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderedModels(t *testing.T) {
+	entries, err := orderedModels(testutil.NewDB(t, nil).DB)
+	if err != nil {
+		t.Fatalf("orderedModels() error = %v", err)
+	}
+	if len(entries) != len(models.Registered()) {
+		t.Fatalf("ordered %d models, want %d", len(entries), len(models.Registered()))
+	}
+
+	// Every foreign key references a table that was migrated before
+	migrated := make(map[string]bool)
+	for _, entry := range entries {
+		for _, relationship := range entry.Schema.Relationships.Relations {
+			constraint := relationship.ParseConstraint()
+			if constraint == nil || constraint.Schema != entry.Schema || constraint.ReferenceSchema.Table == entry.Schema.Table {
+				continue
+			}
+			if !migrated[constraint.ReferenceSchema.Table] {
+				t.Errorf("model %s comes before the table %s it references", entry.Name, constraint.ReferenceSchema.Table)
+			}
+		}
+		migrated[entry.Schema.Table] = true
+	}
+}
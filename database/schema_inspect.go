@@ -0,0 +1,293 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// typeAliases maps PostgreSQL type spellings to the name used for comparisons
+var typeAliases = map[string]string{
+	"character varying":           "varchar",
+	"character":                   "char",
+	"int":                         "integer",
+	"int4":                        "integer",
+	"int8":                        "bigint",
+	"int2":                        "smallint",
+	"serial":                      "integer",
+	"serial4":                     "integer",
+	"bigserial":                   "bigint",
+	"serial8":                     "bigint",
+	"smallserial":                 "smallint",
+	"serial2":                     "smallint",
+	"bool":                        "boolean",
+	"decimal":                     "numeric",
+	"float8":                      "double precision",
+	"float4":                      "real",
+	"timestamp with time zone":    "timestamptz",
+	"timestamp without time zone": "timestamp",
+	"time with time zone":         "timetz",
+	"time without time zone":      "time",
+}
+
+// foreignKeyActions maps pg_constraint action codes to their SQL
+var foreignKeyActions = map[string]string{
+	"a": "NO ACTION",
+	"r": "RESTRICT",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// identifierPattern matches plain column names, which are quoted in index definitions
+// unlike expressions
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// defaultCastPattern matches the casts PostgreSQL adds to stored defaults, e.g. 'x'::character varying
+var defaultCastPattern = regexp.MustCompile(`::[a-z_ ]+(\([0-9, ]+\))?(\[\])?`)
+
+// IndexInfo represents an existing or expected index
+type IndexInfo struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	// Primary and Constraint report indexes backing a primary key or unique constraint
+	Primary    bool
+	Constraint bool
+	// Where is the predicate of a partial index. Predicates are not compared, as
+	// PostgreSQL rewrites them, so a partial index is matched by name.
+	Where string
+	// Expression reports indexes over expressions, which are matched by name only
+	Expression bool
+}
+
+// ForeignKeyInfo represents an existing single-column foreign key constraint
+type ForeignKeyInfo struct {
+	Name             string
+	ColumnName       string
+	ReferencedTable  string
+	ReferencedColumn string
+	OnUpdate         string
+	OnDelete         string
+}
+
+// inspectColumns returns the columns of a table in the current schema in their table order
+func inspectColumns(db *gorm.DB, tableName string) ([]ColumnInfo, error) {
+	var rows []struct {
+		ColumnName             string
+		DataType               string
+		UdtName                string
+		CharacterMaximumLength *int
+		NumericPrecision       *int
+		NumericScale           *int
+		IsNullable             string
+		ColumnDefault          *string
+		IsPrimaryKey           bool
+	}
+	err := db.Raw(`SELECT c.column_name, c.data_type, c.udt_name,
+			c.character_maximum_length, c.numeric_precision, c.numeric_scale,
+			c.is_nullable, c.column_default,
+			EXISTS (
+				SELECT 1 FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage kcu
+					ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+				WHERE tc.constraint_type = 'PRIMARY KEY' AND tc.table_schema = c.table_schema
+					AND tc.table_name = c.table_name AND kcu.column_name = c.column_name
+			) AS is_primary_key
+		FROM information_schema.columns c
+		WHERE c.table_schema = CURRENT_SCHEMA() AND c.table_name = ?
+		ORDER BY c.ordinal_position`, tableName).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect columns of %s: %w", tableName, err)
+	}
+
+	columns := make([]ColumnInfo, 0, len(rows))
+	for _, row := range rows {
+		columnType := row.DataType
+		switch row.DataType {
+		case "character varying", "character":
+			if row.CharacterMaximumLength != nil {
+				columnType = fmt.Sprintf("%s(%d)", row.DataType, *row.CharacterMaximumLength)
+			}
+		case "numeric":
+			if row.NumericPrecision != nil {
+				columnType = fmt.Sprintf("numeric(%d,%d)", *row.NumericPrecision, valueOr(row.NumericScale, 0))
+			}
+		case "USER-DEFINED":
+			columnType = row.UdtName
+		case "ARRAY":
+			columnType = strings.TrimPrefix(row.UdtName, "_") + "[]"
+		}
+
+		column := ColumnInfo{
+			Name:         row.ColumnName,
+			Type:         normalizeColumnType(columnType),
+			IsNullable:   row.IsNullable == "YES",
+			IsPrimaryKey: row.IsPrimaryKey,
+		}
+		if row.ColumnDefault != nil {
+			column.Default = *row.ColumnDefault
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// inspectIndexes returns the indexes of a table in the current schema
+func inspectIndexes(db *gorm.DB, tableName string) ([]IndexInfo, error) {
+	var rows []struct {
+		IndexName    string
+		ColumnNames  string
+		IsUnique     bool
+		IsPrimary    bool
+		IsConstraint bool
+		WhereClause  string
+		IsExpression bool
+	}
+	err := db.Raw(`SELECT i.relname AS index_name,
+			array_to_string(ARRAY(
+				SELECT a.attname FROM unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			), ',') AS column_names,
+			ix.indisunique AS is_unique, ix.indisprimary AS is_primary,
+			EXISTS (
+				SELECT 1 FROM pg_constraint con WHERE con.conindid = ix.indexrelid AND con.contype IN ('p', 'u')
+			) AS is_constraint,
+			COALESCE(pg_get_expr(ix.indpred, ix.indrelid), '') AS where_clause,
+			ix.indexprs IS NOT NULL AS is_expression
+		FROM pg_index ix
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		WHERE t.relname = ? AND t.relnamespace = (SELECT oid FROM pg_namespace WHERE nspname = CURRENT_SCHEMA())
+		ORDER BY i.relname`, tableName).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect indexes of %s: %w", tableName, err)
+	}
+
+	indexes := make([]IndexInfo, 0, len(rows))
+	for _, row := range rows {
+		indexes = append(indexes, IndexInfo{
+			Name:       row.IndexName,
+			Columns:    strings.Split(row.ColumnNames, ","),
+			Unique:     row.IsUnique,
+			Primary:    row.IsPrimary,
+			Constraint: row.IsConstraint,
+			Where:      row.WhereClause,
+			Expression: row.IsExpression,
+		})
+	}
+	return indexes, nil
+}
+
+// inspectForeignKeys returns the single-column foreign keys of a table in the current schema
+func inspectForeignKeys(db *gorm.DB, tableName string) ([]ForeignKeyInfo, error) {
+	var rows []struct {
+		ConstraintName   string
+		ColumnName       string
+		ReferencedTable  string
+		ReferencedColumn string
+		OnUpdate         string
+		OnDelete         string
+	}
+	err := db.Raw(`SELECT con.conname AS constraint_name, a.attname AS column_name,
+			ref.relname AS referenced_table, ra.attname AS referenced_column,
+			con.confupdtype::text AS on_update, con.confdeltype::text AS on_delete
+		FROM pg_constraint con
+		JOIN pg_class t ON t.oid = con.conrelid
+		JOIN pg_class ref ON ref.oid = con.confrelid
+		JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = con.conkey[1]
+		JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = con.confkey[1]
+		WHERE con.contype = 'f' AND array_length(con.conkey, 1) = 1
+			AND t.relname = ? AND t.relnamespace = (SELECT oid FROM pg_namespace WHERE nspname = CURRENT_SCHEMA())
+		ORDER BY con.conname`, tableName).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect foreign keys of %s: %w", tableName, err)
+	}
+
+	foreignKeys := make([]ForeignKeyInfo, 0, len(rows))
+	for _, row := range rows {
+		foreignKeys = append(foreignKeys, ForeignKeyInfo{
+			Name:             row.ConstraintName,
+			ColumnName:       row.ColumnName,
+			ReferencedTable:  row.ReferencedTable,
+			ReferencedColumn: row.ReferencedColumn,
+			OnUpdate:         foreignKeyActions[row.OnUpdate],
+			OnDelete:         foreignKeyActions[row.OnDelete],
+		})
+	}
+	return foreignKeys, nil
+}
+
+// normalizeColumnType spells a column type the way inspectColumns reports it, so model
+// and database types compare equal when PostgreSQL treats them as the same type
+func normalizeColumnType(columnType string) string {
+	columnType = strings.Join(strings.Fields(strings.ToLower(columnType)), " ")
+	name, params, hasParams := strings.Cut(columnType, "(")
+	name = strings.TrimSpace(name)
+	if alias, ok := typeAliases[name]; ok {
+		name = alias
+	}
+	if !hasParams {
+		return name
+	}
+
+	params, suffix, _ := strings.Cut(params, ")")
+	params = strings.ReplaceAll(params, " ", "")
+	switch name {
+	case "numeric":
+		if !strings.Contains(params, ",") {
+			params += ",0"
+		}
+	case "timestamptz", "timestamp", "timetz", "time":
+		// 6 is the default precision
+		if params == "6" {
+			return name + suffix
+		}
+	}
+	return name + "(" + params + ")" + suffix
+}
+
+// normalizeDefault spells a column default the way PostgreSQL stores it without casts.
+// Unquoted defaults of text columns are quoted as GORM quotes them. Empty means no default.
+func normalizeDefault(value interface{}, columnType string) string {
+	text, ok := value.(string)
+	if !ok {
+		if value == nil {
+			return ""
+		}
+		text = fmt.Sprint(value)
+	}
+
+	text = strings.TrimSpace(defaultCastPattern.ReplaceAllString(text, ""))
+	if strings.EqualFold(text, "null") {
+		return ""
+	}
+	if strings.HasPrefix(text, "'") {
+		return text
+	}
+
+	isText := slices.ContainsFunc([]string{"varchar", "char", "text"}, func(prefix string) bool {
+		return strings.HasPrefix(normalizeColumnType(columnType), prefix)
+	})
+	if isText && text != "" && !strings.Contains(text, "(") {
+		return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+	}
+	return strings.ToLower(text)
+}
+
+// quoteIdentifier quotes a table, column, index or constraint name for SQL
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// valueOr dereferences value, or returns fallback when it is nil
+func valueOr[T any](value *T, fallback T) T {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
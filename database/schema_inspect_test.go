@@ -0,0 +1,66 @@
+package database
+
+import "testing"
+
+func TestNormalizeColumnType(t *testing.T) {
+	tests := []struct {
+		columnType string
+		want       string
+	}{
+		{"character varying(255)", "varchar(255)"},
+		{"VARCHAR( 255 )", "varchar(255)"},
+		{"int8", "bigint"},
+		{"bigserial", "bigint"},
+		{"bool", "boolean"},
+		{"decimal(10, 2)", "numeric(10,2)"},
+		{"numeric(10)", "numeric(10,0)"},
+		{"numeric", "numeric"},
+		{"timestamp  with time zone", "timestamptz"},
+		{"timestamptz(6)", "timestamptz"},
+		{"timestamptz(3)", "timestamptz(3)"},
+		{"time without time zone", "time"},
+		{"float8", "double precision"},
+		{"text[]", "text[]"},
+		{"varchar(64)[]", "varchar(64)[]"},
+		{"jsonb", "jsonb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.columnType, func(t *testing.T) {
+			if got := normalizeColumnType(tt.columnType); got != tt.want {
+				t.Errorf("normalizeColumnType(%q) = %q, want %q", tt.columnType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDefault(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      interface{}
+		columnType string
+		want       string
+	}{
+		{name: "no default", value: nil, columnType: "text", want: ""},
+		{name: "null", value: "NULL::character varying", columnType: "varchar(20)", want: ""},
+		{name: "stored text default", value: "'employee'::character varying", columnType: "varchar(20)", want: "'employee'"},
+		{name: "GORM text default", value: "employee", columnType: "varchar(20)", want: "'employee'"},
+		{name: "quoted GORM text default", value: "'employee'", columnType: "character varying(20)", want: "'employee'"},
+		{name: "quote in text default", value: "it's", columnType: "text", want: "'it''s'"},
+		{name: "function call on text", value: "gen_random_uuid()", columnType: "text", want: "gen_random_uuid()"},
+		{name: "boolean", value: "TRUE", columnType: "boolean", want: "true"},
+		{name: "number", value: 0, columnType: "bigint", want: "0"},
+		{name: "numeric cast", value: "0.00::numeric(10, 2)", columnType: "numeric(10,2)", want: "0.00"},
+		{name: "array cast", value: "'{}'::text[]", columnType: "text[]", want: "'{}'"},
+		{name: "function", value: "NOW()", columnType: "timestamptz", want: "now()"},
+		{name: "sequence", value: "nextval('users_id_seq'::regclass)", columnType: "bigint", want: "nextval('users_id_seq')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeDefault(tt.value, tt.columnType); got != tt.want {
+				t.Errorf("normalizeDefault(%v, %q) = %q, want %q", tt.value, tt.columnType, got, tt.want)
+			}
+		})
+	}
+}
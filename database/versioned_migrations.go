@@ -0,0 +1,361 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-fiber-template/helpers"
+	"go-fiber-template/models"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockID is the advisory lock that keeps instances from applying the same migration twice
+const migrationLockID = 7210531
+
+// Migration states reported by VersionedMigrator.Status
+const (
+	MigrationApplied = "applied"
+	MigrationPending = "pending"
+	// MigrationModified is an applied SQL migration whose up file changed since
+	MigrationModified = "modified"
+	// MigrationMissing is an applied migration that is no longer defined
+	MigrationMissing = "missing"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationFunc applies or reverts a migration inside its transaction
+type MigrationFunc func(tx *gorm.DB) error
+
+// VersionedMigration is a schema change applied once and recorded in schema_migrations.
+// SQL migrations are read from migrations/<version>_<name>.up.sql and .down.sql, Go
+// migrations are added with RegisterMigration.
+type VersionedMigration struct {
+	Version  int64
+	Name     string
+	Checksum string
+	// SQL is the up SQL of SQL migrations, shown in migration plans
+	SQL string
+	Up  MigrationFunc
+	// Down is nil when the migration cannot be reverted
+	Down MigrationFunc
+}
+
+// MigrationStatus describes a migration and whether it has been applied
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+var goMigrations []VersionedMigration
+
+// RegisterMigration adds a Go migration, typically from an init function. Go
+// migrations have no checksum, so later edits to them are not detected.
+func RegisterMigration(migration VersionedMigration) {
+	goMigrations = append(goMigrations, migration)
+}
+
+// VersionedMigrator applies versioned migrations in version order
+type VersionedMigrator struct {
+	db         *gorm.DB
+	migrations []VersionedMigration
+}
+
+// NewVersionedMigrator loads the embedded SQL migrations and the registered Go migrations
+func NewVersionedMigrator(db *gorm.DB) (*VersionedMigrator, error) {
+	migrations, err := loadSQLMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+	migrations = append(migrations, goMigrations...)
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version <= 0 || migration.Up == nil {
+			return nil, fmt.Errorf("migration %s needs a positive version and an up migration", migrationName(migration))
+		}
+		if i > 0 && migrations[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("migrations %s and %s share a version", migrationName(migrations[i-1]), migrationName(migration))
+		}
+	}
+
+	return &VersionedMigrator{db: db, migrations: migrations}, nil
+}
+
+// Status lists every defined or applied migration in version order
+func (vm *VersionedMigrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := vm.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(vm.migrations))
+	for _, migration := range vm.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name, State: MigrationPending}
+		if record, ok := applied[migration.Version]; ok {
+			status.State = MigrationApplied
+			if record.Checksum != migration.Checksum {
+				status.State = MigrationModified
+			}
+			status.AppliedAt = &record.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Version:   record.Version,
+			Name:      record.Name,
+			State:     MigrationMissing,
+			AppliedAt: &record.AppliedAt,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied, in version order
+func (vm *VersionedMigrator) Pending(ctx context.Context) ([]VersionedMigration, error) {
+	applied, err := vm.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []VersionedMigration
+	for _, migration := range vm.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration and returns how many were applied
+func (vm *VersionedMigrator) Up(ctx context.Context) (int, error) {
+	return vm.To(ctx, math.MaxInt64)
+}
+
+// Down reverts the given number of most recently applied migrations
+func (vm *VersionedMigrator) Down(ctx context.Context, steps int) (int, error) {
+	statuses, err := vm.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var target int64
+	appliedCount := 0
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].State == MigrationPending {
+			continue
+		}
+		if appliedCount == steps {
+			target = statuses[i].Version
+			break
+		}
+		appliedCount++
+	}
+	return vm.To(ctx, target)
+}
+
+// To reverts the applied migrations after version, newest first, then applies the
+// pending ones up to it, oldest first. It returns how many migrations ran and
+// refuses to start while an applied SQL migration has been modified.
+func (vm *VersionedMigrator) To(ctx context.Context, version int64) (int, error) {
+	if err := vm.db.WithContext(ctx).AutoMigrate(&models.SchemaMigration{}); err != nil {
+		return 0, fmt.Errorf("failed to create the schema_migrations table: %w", err)
+	}
+
+	statuses, err := vm.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	byVersion := make(map[int64]VersionedMigration, len(vm.migrations))
+	for _, migration := range vm.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var toRevert, toApply []MigrationStatus
+	for _, status := range statuses {
+		switch {
+		case status.State == MigrationModified:
+			return 0, fmt.Errorf("migration %d_%s was modified after it was applied", status.Version, status.Name)
+		case status.State == MigrationPending && status.Version <= version:
+			toApply = append(toApply, status)
+		case status.State != MigrationPending && status.Version > version:
+			toRevert = append(toRevert, status)
+		}
+	}
+
+	count := 0
+	for i := len(toRevert) - 1; i >= 0; i-- {
+		migration, ok := byVersion[toRevert[i].Version]
+		if !ok {
+			return count, fmt.Errorf("migration %d_%s cannot be reverted as it is no longer defined", toRevert[i].Version, toRevert[i].Name)
+		}
+		if err := vm.run(ctx, migration, false); err != nil {
+			return count, err
+		}
+		helpers.Success("Reverted migration %s", migrationName(migration))
+		count++
+	}
+	for _, status := range toApply {
+		migration := byVersion[status.Version]
+		if err := vm.run(ctx, migration, true); err != nil {
+			return count, err
+		}
+		helpers.Success("Applied migration %s", migrationName(migration))
+		count++
+	}
+	return count, nil
+}
+
+// run applies or reverts a migration and updates its history row in one transaction.
+// The advisory lock and the history check make concurrent instances skip migrations
+// that another instance has already run.
+func (vm *VersionedMigrator) run(ctx context.Context, migration VersionedMigration, up bool) error {
+	err := vm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.SchemaMigration{}).Where("version = ?", migration.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if up == (count > 0) {
+			return nil
+		}
+
+		if !up {
+			if migration.Down == nil {
+				return fmt.Errorf("it has no down migration")
+			}
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&models.SchemaMigration{}, "version = ?", migration.Version).Error
+		}
+
+		if err := migration.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&models.SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		action := "apply"
+		if !up {
+			action = "revert"
+		}
+		return fmt.Errorf("failed to %s migration %s: %w", action, migrationName(migration), err)
+	}
+	return nil
+}
+
+// applied returns the history rows by version, or none before the history table exists
+func (vm *VersionedMigrator) applied(ctx context.Context) (map[int64]models.SchemaMigration, error) {
+	var records []models.SchemaMigration
+	if !vm.db.WithContext(ctx).Migrator().HasTable(&models.SchemaMigration{}) {
+		return map[int64]models.SchemaMigration{}, nil
+	}
+	if err := vm.db.WithContext(ctx).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load the migration history: %w", err)
+	}
+
+	applied := make(map[int64]models.SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// loadSQLMigrations reads the up and down files in the migrations directory of files
+func loadSQLMigrations(files fs.FS) ([]VersionedMigration, error) {
+	entries, err := fs.ReadDir(files, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*VersionedMigration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		base, up := strings.CutSuffix(fileName, ".up.sql")
+		if !up {
+			var down bool
+			if base, down = strings.CutSuffix(fileName, ".down.sql"); !down {
+				return nil, fmt.Errorf("migration %s must end in .up.sql or .down.sql", fileName)
+			}
+		}
+
+		versionText, name, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionText, 10, 64)
+		if err != nil || version <= 0 || name == "" {
+			return nil, fmt.Errorf("migration %s must be named <version>_<name>", fileName)
+		}
+
+		content, err := fs.ReadFile(files, path.Join("migrations", fileName))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &VersionedMigration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migration %s does not match the name of version %d", fileName, version)
+		}
+
+		if up {
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+			migration.SQL = string(content)
+			migration.Up = execSQL(string(content))
+		} else {
+			migration.Down = execSQL(string(content))
+		}
+	}
+
+	migrations := make([]VersionedMigration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	return migrations, nil
+}
+
+// execSQL returns a migration running the statements of a SQL file. Without
+// arguments the driver sends them in one simple query, so a file may hold several.
+func execSQL(sql string) MigrationFunc {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(sql).Error
+	}
+}
+
+// migrationName identifies a migration in logs and errors
+func migrationName(migration VersionedMigration) string {
+	return fmt.Sprintf("%d_%s", migration.Version, migration.Name)
+}
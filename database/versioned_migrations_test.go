@@ -0,0 +1,353 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"go-fiber-template/testutil"
+
+	"gorm.io/gorm"
+)
+
+// checksumOf returns the checksum recorded for a SQL migration with the given up file
+func checksumOf(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// historyHandler answers the statements of a migrator whose schema_migrations
+// table holds the given versions with their checksums
+func historyHandler(applied map[int64]string) testutil.Handler {
+	return func(query testutil.Query) testutil.Result {
+		switch {
+		case strings.Contains(query.SQL, "information_schema.tables"):
+			return testutil.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(1)}}}
+		case query.SQL == `SELECT * FROM "schema_migrations"`:
+			result := testutil.Result{Columns: []string{"version", "name", "checksum", "applied_at"}}
+			for version, checksum := range applied {
+				result.Rows = append(result.Rows, []driver.Value{version, fmt.Sprintf("v%d", version), checksum, time.Now()})
+			}
+			return result
+		case strings.HasPrefix(query.SQL, `SELECT count(*) FROM "schema_migrations" WHERE version = `):
+			count := int64(0)
+			if _, ok := applied[query.Args[0].(int64)]; ok {
+				count = 1
+			}
+			return testutil.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{count}}}
+		}
+		return testutil.Result{}
+	}
+}
+
+func TestLoadSQLMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		want     []VersionedMigration
+		wantDown []bool
+		wantErr  string
+	}{
+		{
+			name: "up and down files",
+			files: fstest.MapFS{
+				"migrations/2_add_index.up.sql":      file("CREATE INDEX a ON t (c);"),
+				"migrations/2_add_index.down.sql":    file("DROP INDEX a;"),
+				"migrations/10_backfill_rows.up.sql": file("UPDATE t SET c = 1;"),
+			},
+			want: []VersionedMigration{
+				{Version: 2, Name: "add_index", Checksum: checksumOf("CREATE INDEX a ON t (c);"), SQL: "CREATE INDEX a ON t (c);"},
+				{Version: 10, Name: "backfill_rows", Checksum: checksumOf("UPDATE t SET c = 1;"), SQL: "UPDATE t SET c = 1;"},
+			},
+			wantDown: []bool{true, false},
+		},
+		{
+			name:  "down file without up file",
+			files: fstest.MapFS{"migrations/3_drop.down.sql": file("DROP TABLE t;")},
+			want:  []VersionedMigration{{Version: 3, Name: "drop"}},
+		},
+		{
+			name:    "unknown suffix",
+			files:   fstest.MapFS{"migrations/1_init.sql": file("")},
+			wantErr: "must end in .up.sql or .down.sql",
+		},
+		{
+			name:    "missing name",
+			files:   fstest.MapFS{"migrations/1.up.sql": file("")},
+			wantErr: "must be named <version>_<name>",
+		},
+		{
+			name:    "invalid version",
+			files:   fstest.MapFS{"migrations/v1_init.up.sql": file("")},
+			wantErr: "must be named <version>_<name>",
+		},
+		{
+			name: "names of a version differ",
+			files: fstest.MapFS{
+				"migrations/1_init.up.sql":    file(""),
+				"migrations/1_setup.down.sql": file(""),
+			},
+			wantErr: "does not match the name of version 1",
+		},
+		{
+			name:    "no migrations directory",
+			files:   fstest.MapFS{},
+			wantErr: "file does not exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadSQLMigrations(tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadSQLMigrations() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadSQLMigrations() error = %v", err)
+			}
+
+			slices.SortFunc(migrations, func(a, b VersionedMigration) int { return int(a.Version - b.Version) })
+			if len(migrations) != len(tt.want) {
+				t.Fatalf("loaded %d migrations, want %d", len(migrations), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := migrations[i]
+				if got.Version != want.Version || got.Name != want.Name || got.Checksum != want.Checksum || got.SQL != want.SQL {
+					t.Errorf("migration %d = %d_%s (checksum %q), want %d_%s (checksum %q)",
+						i, got.Version, got.Name, got.Checksum, want.Version, want.Name, want.Checksum)
+				}
+				if (got.Up != nil) != (want.SQL != "") {
+					t.Errorf("migration %d has up step %v", i, got.Up != nil)
+				}
+				if tt.wantDown != nil && (got.Down != nil) != tt.wantDown[i] {
+					t.Errorf("migration %d has down step %v, want %v", i, got.Down != nil, tt.wantDown[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrationChecksums(t *testing.T) {
+	migrations, err := loadSQLMigrations(migrationFiles)
+	if err != nil {
+		t.Fatalf("loadSQLMigrations() error = %v", err)
+	}
+	for _, migration := range migrations {
+		t.Run(migrationName(migration), func(t *testing.T) {
+			content, err := fs.ReadFile(migrationFiles, fmt.Sprintf("migrations/%s.up.sql", migrationName(migration)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if migration.Checksum != checksumOf(string(content)) {
+				t.Errorf("checksum %s does not match the up file", migration.Checksum)
+			}
+			if migration.Up == nil || migration.Down == nil {
+				t.Error("migration is missing its up or down step")
+			}
+		})
+	}
+}
+
+func TestNewVersionedMigrator(t *testing.T) {
+	noop := func(*gorm.DB) error { return nil }
+
+	tests := []struct {
+		name       string
+		registered []VersionedMigration
+		wantErr    string
+	}{
+		{
+			name: "Go and SQL migrations in version order",
+			registered: []VersionedMigration{
+				{Version: math.MaxInt64, Name: "last", Up: noop},
+				{Version: 1, Name: "first", Up: noop},
+			},
+		},
+		{
+			name:       "duplicate version",
+			registered: []VersionedMigration{{Version: 20261016090000, Name: "clash", Up: noop}},
+			wantErr:    "share a version",
+		},
+		{
+			name:       "missing up step",
+			registered: []VersionedMigration{{Version: 5, Name: "empty"}},
+			wantErr:    "needs a positive version and an up migration",
+		},
+		{
+			name:       "version zero",
+			registered: []VersionedMigration{{Version: 0, Name: "zero", Up: noop}},
+			wantErr:    "needs a positive version and an up migration",
+		},
+	}
+
+	registered := goMigrations
+	t.Cleanup(func() { goMigrations = registered })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goMigrations = append(slices.Clone(registered), tt.registered...)
+
+			migrator, err := NewVersionedMigrator(nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewVersionedMigrator() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewVersionedMigrator() error = %v", err)
+			}
+
+			var versions []int64
+			for _, migration := range migrator.migrations {
+				versions = append(versions, migration.Version)
+			}
+			sqlMigrations, _ := loadSQLMigrations(migrationFiles)
+			if len(versions) != len(sqlMigrations)+len(goMigrations) || !slices.IsSorted(versions) {
+				t.Errorf("versions = %v, want every migration in version order", versions)
+			}
+			if versions[0] != 1 || versions[len(versions)-1] != math.MaxInt64 {
+				t.Errorf("versions = %v, want the registered migrations first and last", versions)
+			}
+		})
+	}
+}
+
+func TestVersionedMigratorStatus(t *testing.T) {
+	migrations := []VersionedMigration{
+		{Version: 1, Name: "v1", Checksum: "a"},
+		{Version: 2, Name: "v2", Checksum: "b"},
+		{Version: 4, Name: "v4", Checksum: "d"},
+	}
+	db := testutil.NewDB(t, historyHandler(map[int64]string{1: "a", 2: "changed", 3: "c"}))
+	migrator := &VersionedMigrator{db: db.DB, migrations: migrations}
+
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	want := []struct {
+		version int64
+		state   string
+	}{
+		{1, MigrationApplied},
+		{2, MigrationModified},
+		{3, MigrationMissing},
+		{4, MigrationPending},
+	}
+	if len(statuses) != len(want) {
+		t.Fatalf("got %d statuses, want %d", len(statuses), len(want))
+	}
+	for i, status := range statuses {
+		if status.Version != want[i].version || status.State != want[i].state {
+			t.Errorf("status %d = %d %s, want %d %s", i, status.Version, status.State, want[i].version, want[i].state)
+		}
+		if (status.AppliedAt == nil) != (status.State == MigrationPending) {
+			t.Errorf("status %d AppliedAt = %v", i, status.AppliedAt)
+		}
+	}
+}
+
+func TestVersionedMigratorTo(t *testing.T) {
+	tests := []struct {
+		name      string
+		applied   map[int64]string
+		target    int64
+		wantSteps []string
+		wantErr   string
+	}{
+		{
+			name:      "applies pending migrations oldest first",
+			applied:   map[int64]string{1: "1"},
+			target:    3,
+			wantSteps: []string{"up 2", "up 3"},
+		},
+		{
+			name:      "stops at the target",
+			applied:   map[int64]string{},
+			target:    2,
+			wantSteps: []string{"up 1", "up 2"},
+		},
+		{
+			name:      "reverts newest first",
+			applied:   map[int64]string{1: "1", 2: "2", 3: "3"},
+			target:    1,
+			wantSteps: []string{"down 3", "down 2"},
+		},
+		{
+			name:      "reverts everything",
+			applied:   map[int64]string{1: "1", 2: "2"},
+			target:    0,
+			wantSteps: []string{"down 2", "down 1"},
+		},
+		{
+			name:    "refuses to run with a modified migration",
+			applied: map[int64]string{1: "edited"},
+			target:  3,
+			wantErr: "migration 1_v1 was modified after it was applied",
+		},
+		{
+			name:    "cannot revert a migration that is no longer defined",
+			applied: map[int64]string{1: "1", 9: "9"},
+			target:  1,
+			wantErr: "migration 9_v9 cannot be reverted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var steps []string
+			step := func(name string) MigrationFunc {
+				return func(*gorm.DB) error {
+					steps = append(steps, name)
+					return nil
+				}
+			}
+			var migrations []VersionedMigration
+			for version := int64(1); version <= 3; version++ {
+				migrations = append(migrations, VersionedMigration{
+					Version:  version,
+					Name:     fmt.Sprintf("v%d", version),
+					Checksum: fmt.Sprint(version),
+					Up:       step(fmt.Sprintf("up %d", version)),
+					Down:     step(fmt.Sprintf("down %d", version)),
+				})
+			}
+
+			db := testutil.NewDB(t, historyHandler(tt.applied))
+			migrator := &VersionedMigrator{db: db.DB, migrations: migrations}
+
+			count, err := migrator.To(context.Background(), tt.target)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("To() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("To() error = %v", err)
+			}
+			if !slices.Equal(steps, tt.wantSteps) || count != len(tt.wantSteps) {
+				t.Errorf("To() ran %v (count %d), want %v", steps, count, tt.wantSteps)
+			}
+			if got := len(db.Matching("pg_advisory_xact_lock")); got != len(tt.wantSteps) {
+				t.Errorf("took the advisory lock %d times, want %d", got, len(tt.wantSteps))
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-fiber-template/config"
+	"go-fiber-template/models"
+	"go-fiber-template/requests"
+
+	"gorm.io/gorm"
+)
+
+// OverflowPolicy decides what Log does when the buffer is full
+type OverflowPolicy string
+
+const (
+	// OverflowBlock makes Log wait until there is room in the buffer
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest discards the oldest buffered entry to make room
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowDropNewest discards the entry being logged
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+)
+
+// AsyncLoggerConfig configures buffering and batching of the async logger
+type AsyncLoggerConfig struct {
+	BufferSize     int
+	BatchSize      int
+	FlushInterval  time.Duration
+	OverflowPolicy OverflowPolicy
+}
+
+// DefaultAsyncLoggerConfig returns the default async logger configuration
+func DefaultAsyncLoggerConfig() AsyncLoggerConfig {
+	return AsyncLoggerConfig{
+		BufferSize:     1000,
+		BatchSize:      100,
+		FlushInterval:  2 * time.Second,
+		OverflowPolicy: OverflowDropNewest,
+	}
+}
+
+// AsyncLoggerConfigFrom builds the async logger configuration from the application settings
+func AsyncLoggerConfigFrom(settings config.AsyncLogConfig) AsyncLoggerConfig {
+	loggerConfig := DefaultAsyncLoggerConfig()
+	if settings.BufferSize > 0 {
+		loggerConfig.BufferSize = settings.BufferSize
+	}
+	if settings.BatchSize > 0 {
+		loggerConfig.BatchSize = settings.BatchSize
+	}
+	if settings.FlushInterval > 0 {
+		loggerConfig.FlushInterval = settings.FlushInterval
+	}
+	if settings.OverflowPolicy != "" {
+		loggerConfig.OverflowPolicy = OverflowPolicy(settings.OverflowPolicy)
+	}
+	return loggerConfig
+}
+
+// AsyncLogger handles database logging. Entries are buffered in memory and
+// written in batches by ProcessLog; Shutdown drains the buffer.
+type AsyncLogger struct {
+	db       *gorm.DB
+	config   AsyncLoggerConfig
+	channel  chan requests.LogEntry
+	quit     chan struct{}
+	done     chan struct{}
+	quitOnce sync.Once
+	closed   atomic.Bool
+	dropped  atomic.Uint64
+}
+
+// NewAsyncLogger creates a new async logger instance with the application configuration
+func NewAsyncLogger(db *gorm.DB) *AsyncLogger {
+	return NewAsyncLoggerWithConfig(db, AsyncLoggerConfigFrom(config.Get().AsyncLog))
+}
+
+// NewAsyncLoggerWithConfig creates a new async logger instance with the given configuration
+func NewAsyncLoggerWithConfig(db *gorm.DB, config AsyncLoggerConfig) *AsyncLogger {
+	defaults := DefaultAsyncLoggerConfig()
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaults.BufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.OverflowPolicy == "" {
+		config.OverflowPolicy = defaults.OverflowPolicy
+	}
+
+	return &AsyncLogger{
+		db:      db,
+		config:  config,
+		channel: make(chan requests.LogEntry, config.BufferSize), // Buffered channel
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// ProcessLog writes buffered entries in batches until Shutdown is called.
+// A batch is flushed when it reaches BatchSize or every FlushInterval.
+func (logger *AsyncLogger) ProcessLog() {
+	Info("🚀 Starting asynchronous logger...")
+	defer close(logger.done)
+
+	ticker := time.NewTicker(logger.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.Log, 0, logger.config.BatchSize)
+	for {
+		select {
+		case entry := <-logger.channel:
+			batch = append(batch, toLogModel(entry))
+			if len(batch) >= logger.config.BatchSize {
+				batch = logger.flush(batch)
+			}
+		case <-ticker.C:
+			batch = logger.flush(batch)
+		case <-logger.quit:
+			// Drain whatever is still buffered
+			for {
+				select {
+				case entry := <-logger.channel:
+					batch = append(batch, toLogModel(entry))
+					if len(batch) >= logger.config.BatchSize {
+						batch = logger.flush(batch)
+					}
+				default:
+					logger.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// Log pushes a log entry into the buffer, applying the overflow policy when it is full
+func (logger *AsyncLogger) Log(entry requests.LogEntry) {
+	if logger.closed.Load() {
+		logger.dropped.Add(1)
+		return
+	}
+
+	switch logger.config.OverflowPolicy {
+	case OverflowBlock:
+		select {
+		case logger.channel <- entry:
+		case <-logger.quit:
+			logger.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case logger.channel <- entry:
+				return
+			default:
+			}
+			select {
+			case <-logger.channel:
+				logger.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case logger.channel <- entry:
+		default:
+			logger.dropped.Add(1)
+		}
+	}
+}
+
+// Shutdown stops accepting entries and waits until the buffer is written or ctx is done
+func (logger *AsyncLogger) Shutdown(ctx context.Context) error {
+	logger.closed.Store(true)
+	logger.quitOnce.Do(func() {
+		close(logger.quit)
+	})
+
+	select {
+	case <-logger.done:
+		if dropped := logger.Dropped(); dropped > 0 {
+			Warning("Asynchronous logger dropped %d log entries", dropped)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dropped returns the number of entries discarded because the buffer was full or
+// closed, or because they could not be inserted
+func (logger *AsyncLogger) Dropped() uint64 {
+	return logger.dropped.Load()
+}
+
+// QueueDepth returns the number of entries waiting in the buffer
+func (logger *AsyncLogger) QueueDepth() int {
+	return len(logger.channel)
+}
+
+// QueueCapacity returns the size of the buffer
+func (logger *AsyncLogger) QueueCapacity() int {
+	return cap(logger.channel)
+}
+
+// HealthCheck fails when the buffer is filled beyond maxFillRatio (0-1)
+func (logger *AsyncLogger) HealthCheck(maxFillRatio float64) HealthCheck {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		depth, capacity := logger.QueueDepth(), logger.QueueCapacity()
+		details := map[string]interface{}{
+			"queue_depth":    depth,
+			"queue_capacity": capacity,
+			"dropped":        logger.Dropped(),
+		}
+		if logger.closed.Load() {
+			return details, errors.New("logger is shut down")
+		}
+		if capacity > 0 && float64(depth)/float64(capacity) > maxFillRatio {
+			return details, fmt.Errorf("queue is %d%% full", depth*100/capacity)
+		}
+		return details, nil
+	}
+}
+
+// flush inserts the batch, retrying once before dropping it, and returns it emptied for reuse
+func (logger *AsyncLogger) flush(batch []models.Log) []models.Log {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	err := logger.db.CreateInBatches(batch, len(batch)).Error
+	if err != nil {
+		// A failed insert leaves the primary keys of the batch unset, so it can be retried
+		err = logger.db.CreateInBatches(batch, len(batch)).Error
+	}
+	if err != nil {
+		logger.dropped.Add(uint64(len(batch)))
+		Error(fmt.Sprintf("Failed to insert %d log entries, dropping them", len(batch)), err)
+	} else {
+		Debug("Inserted %d log entries", len(batch))
+	}
+
+	return batch[:0]
+}
+
+// toLogModel converts a log entry into its database model
+func toLogModel(entry requests.LogEntry) models.Log {
+	return models.Log{
+		Method:          entry.Method,
+		URL:             entry.URL,
+		RequestBody:     entry.RequestBody,
+		ResponseBody:    entry.ResponseBody,
+		RequestHeaders:  entry.RequestHeaders,
+		ResponseHeaders: entry.ResponseHeaders,
+		StatusCode:      entry.StatusCode,
+		Route:           entry.Route,
+		UserID:          entry.UserID,
+		IP:              entry.IP,
+		DurationMs:      float64(entry.Duration.Microseconds()) / 1000,
+		RequestID:       entry.RequestID,
+		CreatedAt:       entry.CreatedAt,
+	}
+}
@@ -0,0 +1,183 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-fiber-template/requests"
+	"go-fiber-template/testutil"
+)
+
+// bufferedURLs empties the buffer of the logger and returns the URLs of its entries
+func bufferedURLs(logger *AsyncLogger) []string {
+	var urls []string
+	for len(logger.channel) > 0 {
+		urls = append(urls, (<-logger.channel).URL)
+	}
+	return urls
+}
+
+// insertedEntries counts the log entries sent in INSERT statements
+func insertedEntries(db *testutil.DB) int {
+	count := 0
+	for _, query := range db.Matching(`INSERT INTO "logs"`) {
+		for _, arg := range query.Args {
+			if arg == "GET" {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestAsyncLoggerOverflow(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      OverflowPolicy
+		closed      bool
+		wantURLs    []string
+		wantDropped uint64
+	}{
+		{name: "drop newest", policy: OverflowDropNewest, wantURLs: []string{"/1", "/2"}, wantDropped: 1},
+		{name: "drop oldest", policy: OverflowDropOldest, wantURLs: []string{"/2", "/3"}, wantDropped: 1},
+		{name: "closed logger drops everything", policy: OverflowBlock, closed: true, wantDropped: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewAsyncLoggerWithConfig(nil, AsyncLoggerConfig{BufferSize: 2, OverflowPolicy: tt.policy})
+			logger.closed.Store(tt.closed)
+
+			for i := 1; i <= 3; i++ {
+				logger.Log(requests.LogEntry{URL: fmt.Sprintf("/%d", i)})
+			}
+
+			if got := bufferedURLs(logger); !slices.Equal(got, tt.wantURLs) {
+				t.Errorf("buffered %v, want %v", got, tt.wantURLs)
+			}
+			if got := logger.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %d, want %d", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestAsyncLoggerOverflowBlock(t *testing.T) {
+	tests := []struct {
+		name        string
+		release     func(logger *AsyncLogger)
+		wantURLs    []string
+		wantDropped uint64
+	}{
+		{
+			name:     "waits for room",
+			release:  func(logger *AsyncLogger) { <-logger.channel },
+			wantURLs: []string{"/2"},
+		},
+		{
+			name: "gives up on shutdown",
+			release: func(logger *AsyncLogger) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				logger.Shutdown(ctx)
+			},
+			wantURLs:    []string{"/1"},
+			wantDropped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewAsyncLoggerWithConfig(nil, AsyncLoggerConfig{BufferSize: 1, OverflowPolicy: OverflowBlock})
+			logger.Log(requests.LogEntry{URL: "/1"})
+
+			returned := make(chan struct{})
+			go func() {
+				logger.Log(requests.LogEntry{URL: "/2"})
+				close(returned)
+			}()
+
+			select {
+			case <-returned:
+				t.Fatal("Log returned while the buffer was full")
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			tt.release(logger)
+			select {
+			case <-returned:
+			case <-time.After(time.Second):
+				t.Fatal("Log is still blocked")
+			}
+
+			if got := bufferedURLs(logger); !slices.Equal(got, tt.wantURLs) {
+				t.Errorf("buffered %v, want %v", got, tt.wantURLs)
+			}
+			if got := logger.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %d, want %d", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestAsyncLoggerShutdownDrain(t *testing.T) {
+	tests := []struct {
+		name         string
+		entries      int
+		failures     int32
+		wantInserted int
+		wantInserts  int
+		wantDropped  uint64
+	}{
+		{name: "writes every buffered entry in batches", entries: 7, wantInserted: 7, wantInserts: 3},
+		{name: "empty buffer", entries: 0, wantInserts: 0},
+		{name: "retries a failed batch once", entries: 2, failures: 1, wantInserted: 4, wantInserts: 2},
+		{name: "drops a batch failing twice", entries: 2, failures: 2, wantInserted: 4, wantInserts: 2, wantDropped: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failures atomic.Int32
+			failures.Store(tt.failures)
+			db := testutil.NewDB(t, func(testutil.Query) testutil.Result {
+				if failures.Add(-1) >= 0 {
+					return testutil.Result{Err: errors.New("connection lost")}
+				}
+				return testutil.Result{Columns: []string{"id"}}
+			})
+
+			logger := NewAsyncLoggerWithConfig(db.DB, AsyncLoggerConfig{
+				BufferSize:    10,
+				BatchSize:     3,
+				FlushInterval: time.Hour,
+			})
+			for i := 0; i < tt.entries; i++ {
+				logger.Log(requests.LogEntry{Method: "GET", URL: fmt.Sprintf("/%d", i), CreatedAt: time.Now()})
+			}
+			go logger.ProcessLog()
+
+			if err := logger.Shutdown(context.Background()); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+
+			// Failed attempts are counted too, as the statement was sent
+			if got := insertedEntries(db); got != tt.wantInserted {
+				t.Errorf("sent %d entries, want %d", got, tt.wantInserted)
+			}
+			if got := len(db.Matching(`INSERT INTO "logs"`)); got != tt.wantInserts {
+				t.Errorf("sent %d inserts, want %d", got, tt.wantInserts)
+			}
+			if got := logger.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped() = %d, want %d", got, tt.wantDropped)
+			}
+			if logger.QueueDepth() != 0 {
+				t.Errorf("QueueDepth() = %d after shutdown, want 0", logger.QueueDepth())
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"errors"
+	"time"
+
+	"go-fiber-template/config"
+	"go-fiber-template/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthClaims represents the claims carried by an access token
+type AuthClaims struct {
+	UserID uint            `json:"id"`
+	Email  string          `json:"email"`
+	Role   models.UserType `json:"role"`
+	// IssuedAtNano is the issue time in Unix nanoseconds. iat only has whole seconds,
+	// which cannot tell a token issued right after a logout-all from one issued before it.
+	IssuedAtNano int64 `json:"iat_ns,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// HasRole reports whether the authenticated user has one of the given roles
+func (c *AuthClaims) HasRole(roles ...models.UserType) bool {
+	for _, role := range roles {
+		if c.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMinRole reports whether the authenticated user's role is at least the given role
+func (c *AuthClaims) HasMinRole(role models.UserType) bool {
+	return c.Role.AtLeast(role)
+}
+
+// ExpiresAtTime returns the token expiry, or the zero time if it is not set
+func (c *AuthClaims) ExpiresAtTime() time.Time {
+	if c.ExpiresAt == nil {
+		return time.Time{}
+	}
+	return c.ExpiresAt.Time
+}
+
+// IssuedAtTime returns the issue time at the highest precision the token carries,
+// or the zero time if it is not set
+func (c *AuthClaims) IssuedAtTime() time.Time {
+	if c.IssuedAtNano != 0 {
+		return time.Unix(0, c.IssuedAtNano)
+	}
+	if c.IssuedAt == nil {
+		return time.Time{}
+	}
+	return c.IssuedAt.Time
+}
+
+// ParseJWTToken validates an access token and returns its claims
+func ParseJWTToken(tokenString string) (*AuthClaims, error) {
+	claims := &AuthClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(config.Get().Auth.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// GetAuthUser returns the claims of the authenticated user set by middleware.Protected
+func GetAuthUser(c *fiber.Ctx) *AuthClaims {
+	claims, ok := c.Locals("user").(*AuthClaims)
+	if !ok {
+		return nil
+	}
+	return claims
+}
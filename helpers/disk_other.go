@@ -0,0 +1,8 @@
+//go:build !unix
+
+package helpers
+
+// diskUsage is not implemented on this platform
+func diskUsage(path string) (diskSpace, error) {
+	return diskSpace{}, errDiskUsageUnsupported
+}
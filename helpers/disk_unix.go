@@ -0,0 +1,17 @@
+//go:build unix
+
+package helpers
+
+import "syscall"
+
+// diskUsage returns the space available to unprivileged users on the file system holding path
+func diskUsage(path string) (diskSpace, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return diskSpace{}, err
+	}
+	return diskSpace{
+		Free:  uint64(stat.Bavail) * uint64(stat.Bsize),
+		Total: uint64(stat.Blocks) * uint64(stat.Bsize),
+	}, nil
+}
@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-fiber-template/apperrors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ErrorHandler is the application's fiber.Config.ErrorHandler. It renders every
+// error returned by a handler in the Response envelope. Server errors are logged
+// with their cause, which is never sent to the client.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	appErr := toAppError(err)
+
+	if appErr.Status >= fiber.StatusInternalServerError {
+		WithRequest(c).Error(appErr.Message+": "+detailOf(appErr), appErr.Unwrap())
+	} else if cause := appErr.Unwrap(); cause != nil {
+		WithRequest(c).Debug("%s: %v", appErr.Message, cause)
+	}
+
+	for key, value := range appErr.Headers {
+		c.Set(key, value)
+	}
+	return c.Status(appErr.Status).JSON(Response{
+		Success:   false,
+		Code:      appErr.Code,
+		Message:   appErr.Message,
+		Errors:    appErr.Details,
+		Fields:    appErr.Fields,
+		RequestID: GetRequestID(c),
+	})
+}
+
+// toAppError converts any handler error to an *apperrors.Error
+func toAppError(err error) *apperrors.Error {
+	if appErr, ok := apperrors.As(err); ok {
+		return appErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return apperrors.New(fiberErr.Code, apperrors.CodeForStatus(fiberErr.Code),
+			statusMessage(fiberErr.Code), fiberErr.Message)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NotFound("Record not found").WithCause(err)
+	}
+
+	return apperrors.Internal("An unexpected error occurred", err)
+}
+
+// statusMessage returns the status text in the sentence case used by every error message
+func statusMessage(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "Error"
+	}
+	return text[:1] + strings.ToLower(text[1:])
+}
+
+// detailOf returns the first detail of an error for log messages
+func detailOf(appErr *apperrors.Error) string {
+	if len(appErr.Details) > 0 {
+		return appErr.Details[0]
+	}
+	return appErr.Code
+}
@@ -1,126 +1,138 @@
 package helpers
 
 import (
-	"fmt"
-	"os"
+	"log/slog"
 	"time"
 
+	"go-fiber-template/apperrors"
+	"go-fiber-template/config"
+	"go-fiber-template/models"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // Response types for consistent API responses
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Errors  []string    `json:"errors,omitempty"`
+	Success   bool                   `json:"success"`
+	Code      string                 `json:"code,omitempty"`
+	Message   string                 `json:"message,omitempty"`
+	Data      interface{}            `json:"data,omitempty"`
+	Meta      interface{}            `json:"meta,omitempty"`
+	Errors    []string               `json:"errors,omitempty"`
+	Fields    []apperrors.FieldError `json:"fields,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+}
+
+// Pagination describes a page of results in list responses
+type Pagination struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
 }
 
-// GenerateJWTToken generates a new JWT token for a user
-func GenerateJWTToken(userID uint, email string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"id":    userID,
-		"email": email,
-		"exp":   time.Now().Add(time.Hour * 24).Unix(),
+// NewPagination builds pagination metadata from the page, page size and total count
+func NewPagination(page, perPage int, total int64) Pagination {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = int((total + int64(perPage) - 1) / int64(perPage))
+	}
+	return Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+}
+
+// GenerateJWTToken generates a new short-lived access token for a user
+func GenerateJWTToken(userID uint, email string, role models.UserType) (string, error) {
+	jti, err := GenerateTokenFamily()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, AuthClaims{
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		IssuedAtNano: now.UnixNano(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL())),
+		},
 	})
 
-	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	return token.SignedString([]byte(config.Get().Auth.JWTSecret))
 }
 
 // SuccessResponse sends a success response with data
 func SuccessResponse(c *fiber.Ctx, status int, message string, data interface{}) error {
 	return c.Status(status).JSON(Response{
-		Success: true,
-		Message: message,
-		Data:    data,
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		RequestID: GetRequestID(c),
 	})
 }
 
-// ErrorResponse sends an error response
-func ErrorResponse(c *fiber.Ctx, status int, message string, errors []string) error {
+// SuccessResponseWithMeta sends a success response with data and metadata such as pagination
+func SuccessResponseWithMeta(c *fiber.Ctx, status int, message string, data interface{}, meta interface{}) error {
 	return c.Status(status).JSON(Response{
-		Success: false,
-		Message: message,
-		Errors:  errors,
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Meta:      meta,
+		RequestID: GetRequestID(c),
 	})
 }
 
-// ValidationErrorResponse sends a validation error response
-func ValidationErrorResponse(c *fiber.Ctx, errors []string) error {
-	return ErrorResponse(c, fiber.StatusBadRequest, "Validation failed", errors)
+// PaginatedResponse sends a success response with a page of data and pagination metadata
+func PaginatedResponse(c *fiber.Ctx, message string, data interface{}, pagination Pagination) error {
+	return SuccessResponseWithMeta(c, fiber.StatusOK, message, data, pagination)
 }
 
-// LogRequest logs HTTP request details
-func LogRequest(method, path, ip, userAgent string, status int, duration time.Duration) {
-	// Create a more readable console output
-	var statusColor string
-	var statusIcon string
+// RequestLogEvent holds the fields of a structured HTTP request log event
+type RequestLogEvent struct {
+	Method    string
+	Path      string
+	Status    int
+	Latency   time.Duration
+	IP        string
+	UserAgent string
+	UserID    uint
+	RequestID string
+}
 
+// LogRequest logs HTTP request details as a structured event
+func LogRequest(event RequestLogEvent) {
+	level := slog.LevelInfo
 	switch {
-	case status >= 200 && status < 300:
-		statusColor = "✅"
-		statusIcon = "SUCCESS"
-	case status >= 300 && status < 400:
-		statusColor = "🔄"
-		statusIcon = "REDIRECT"
-	case status >= 400 && status < 500:
-		statusColor = "⚠️"
-		statusIcon = "CLIENT_ERROR"
-	case status >= 500:
-		statusColor = "❌"
-		statusIcon = "SERVER_ERROR"
-	default:
-		statusColor = "❓"
-		statusIcon = "UNKNOWN"
+	case event.Status >= 500:
+		level = slog.LevelError
+	case event.Status >= 400:
+		level = slog.LevelWarn
 	}
 
-	// Console output with colors and formatting
-	consoleMessage := fmt.Sprintf("%s [%s] %s %s - %d (%s) - %s - %v",
-		statusColor,
-		time.Now().Format("15:04:05"),
-		method,
-		path,
-		status,
-		statusIcon,
-		ip,
-		duration)
-
-	// File output (original format)
-	fileMessage := fmt.Sprintf("[HTTP] %s %s - %d - %s - %s - %v", method, path, status, ip, userAgent, duration)
-
-	// Log to console with custom formatting
-	fmt.Println(consoleMessage)
-
-	// Also log to file using the existing logger
-	if status >= 400 {
-		Error(fileMessage, nil)
-	} else {
-		Info(fileMessage)
+	fields := []any{
+		"method", event.Method,
+		"path", event.Path,
+		"status", event.Status,
+		"latency_ms", float64(event.Latency.Microseconds()) / 1000,
+		"ip", event.IP,
+		"user_agent", event.UserAgent,
 	}
-}
-
-// UnauthorizedResponse sends an unauthorized error response
-func UnauthorizedResponse(c *fiber.Ctx) error {
-	return ErrorResponse(c, fiber.StatusUnauthorized, "Unauthorized", []string{"Invalid credentials"})
-}
-
-// ServerErrorResponse sends a server error response
-func ServerErrorResponse(c *fiber.Ctx, message string) error {
-	return ErrorResponse(c, fiber.StatusInternalServerError, "Server error", []string{message})
-}
-
-// GetAuthUser extracts user claims from JWT token
-func GetAuthUser(c *fiber.Ctx) jwt.MapClaims {
-	user := c.Locals("user")
-	if user == nil {
-		return nil
+	if event.UserID != 0 {
+		fields = append(fields, "user_id", event.UserID)
 	}
-	claims, ok := user.(jwt.MapClaims)
-	if !ok {
-		return nil
+	if event.RequestID != "" {
+		fields = append(fields, "request_id", event.RequestID)
 	}
-	return claims
+
+	logMessage(level, "", "http_request", fields)
 }
 
 // ExtractBearerToken extracts the token from Authorization header
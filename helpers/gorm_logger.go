@@ -0,0 +1,110 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go-fiber-template/config"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger sends GORM query logs through the logging backend, tagging each
+// query with the request ID carried by the statement context. Query parameters
+// are never logged.
+type GormLogger struct {
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger creates a GORM logger from the database log level
+// (silent|error|warn|info) and slow query threshold settings
+func NewGormLogger(settings config.DatabaseConfig) *GormLogger {
+	level := gormlogger.Warn
+	switch strings.ToLower(settings.LogLevel) {
+	case "silent":
+		level = gormlogger.Silent
+	case "error":
+		level = gormlogger.Error
+	case "info":
+		level = gormlogger.Info
+	}
+
+	return &GormLogger{
+		level:         level,
+		slowThreshold: settings.SlowQueryThreshold,
+	}
+}
+
+// LogMode implements gormlogger.Interface
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+// Info implements gormlogger.Interface
+func (l *GormLogger) Info(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		WithContext(ctx).Info(format, args...)
+	}
+}
+
+// Warn implements gormlogger.Interface
+func (l *GormLogger) Warn(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		WithContext(ctx).Warning(format, args...)
+	}
+}
+
+// Error implements gormlogger.Interface
+func (l *GormLogger) Error(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		WithContext(ctx).Error(fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Trace implements gormlogger.Interface
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	level := slog.LevelDebug
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		level = slog.LevelError
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		level = slog.LevelWarn
+	case l.level >= gormlogger.Info:
+		level = slog.LevelDebug
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	fields := []any{
+		"sql", sql,
+		"rows", rows,
+		"duration_ms", float64(elapsed.Microseconds()) / 1000,
+	}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, "request_id", requestID)
+	}
+	if level == slog.LevelError {
+		fields = append(fields, "error", err.Error())
+	}
+
+	logMessage(level, "🗄️ ", "db_query", fields)
+}
+
+// ParamsFilter implements gorm's ParamsFilter so query values are never logged
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
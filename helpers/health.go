@@ -0,0 +1,165 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HealthCheck reports whether a dependency is usable. The returned details
+// are included in the readiness report even when the check passes.
+type HealthCheck func(ctx context.Context) (map[string]interface{}, error)
+
+// HealthCheckResult is the outcome of a single readiness check
+type HealthCheckResult struct {
+	Status    string                 `json:"status"`
+	LatencyMs float64                `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// ReadinessReport is the outcome of every readiness check
+type ReadinessReport struct {
+	Ready  bool                         `json:"ready"`
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+var (
+	healthMu     sync.RWMutex
+	healthChecks = make(map[string]HealthCheck)
+	startedAt    = time.Now()
+)
+
+// RegisterHealthCheck adds a readiness check, replacing any check with the same name
+func RegisterHealthCheck(name string, check HealthCheck) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	healthChecks[name] = check
+}
+
+// Uptime returns how long the process has been running
+func Uptime() time.Duration {
+	return time.Since(startedAt)
+}
+
+// CheckReadiness runs every registered check concurrently, each limited by timeout.
+// The application is never ready once shutdown has started.
+func CheckReadiness(ctx context.Context, timeout time.Duration) ReadinessReport {
+	healthMu.RLock()
+	names := make([]string, 0, len(healthChecks))
+	for name := range healthChecks {
+		names = append(names, name)
+	}
+	checks := make(map[string]HealthCheck, len(healthChecks))
+	for name, check := range healthChecks {
+		checks[name] = check
+	}
+	healthMu.RUnlock()
+	sort.Strings(names)
+
+	results := make([]HealthCheckResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, check, timeout)
+		}(i, checks[name])
+	}
+	wg.Wait()
+
+	report := ReadinessReport{Ready: true, Status: "ready", Checks: make(map[string]HealthCheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != HealthStatusOK {
+			report.Ready = false
+			report.Status = "not_ready"
+		}
+	}
+	if IsShuttingDown() {
+		report.Ready = false
+		report.Status = "shutting_down"
+	}
+	return report
+}
+
+// runHealthCheck runs a check, recovering from panics and enforcing the timeout
+func runHealthCheck(ctx context.Context, check HealthCheck, timeout time.Duration) HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		details map[string]interface{}
+		err     error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("check panicked: %v", r)}
+			}
+		}()
+		details, err := check(ctx)
+		done <- outcome{details: details, err: err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = ctx.Err()
+	}
+
+	checkResult := HealthCheckResult{
+		Status:    HealthStatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   result.details,
+	}
+	if result.err != nil {
+		checkResult.Status = HealthStatusFail
+		checkResult.Error = result.err.Error()
+	}
+	return checkResult
+}
+
+// DiskSpaceCheck fails when the file system holding dir has less than minFreeBytes available
+func DiskSpaceCheck(dir string, minFreeBytes uint64) HealthCheck {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		usage, err := diskUsage(dir)
+		if errors.Is(err, errDiskUsageUnsupported) {
+			return map[string]interface{}{"path": dir, "supported": false}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		details := map[string]interface{}{
+			"path":        dir,
+			"free_bytes":  usage.Free,
+			"total_bytes": usage.Total,
+		}
+		if usage.Free < minFreeBytes {
+			return details, fmt.Errorf("only %d bytes free, need at least %d", usage.Free, minFreeBytes)
+		}
+		return details, nil
+	}
+}
+
+// diskSpace describes the capacity of a file system
+type diskSpace struct {
+	Free  uint64
+	Total uint64
+}
+
+var errDiskUsageUnsupported = errors.New("disk usage is not supported on this platform")
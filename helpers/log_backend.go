@@ -0,0 +1,288 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go-fiber-template/config"
+)
+
+const (
+	// LogFormatText writes human readable key=value lines
+	LogFormatText = "text"
+	// LogFormatJSON writes one JSON object per line
+	LogFormatJSON = "json"
+)
+
+// LogConfig configures the logging backend behind Info, Warning, Error, Success and Debug
+type LogConfig struct {
+	Format string
+	Level  slog.Level
+	// PackageLevels overrides Level for log calls made from the named packages,
+	// e.g. {"database": slog.LevelWarn}
+	PackageLevels map[string]slog.Level
+}
+
+// DefaultLogConfig returns the text backend at debug level
+func DefaultLogConfig() LogConfig {
+	return LogConfig{Format: LogFormatText, Level: slog.LevelDebug}
+}
+
+// LogConfigFrom builds the logging backend configuration from the application
+// settings, e.g. a Level of "info" and PackageLevels of "database=warn,middleware=debug"
+func LogConfigFrom(settings config.LogConfig) (LogConfig, error) {
+	logConfig := DefaultLogConfig()
+	if settings.Format != "" {
+		logConfig.Format = strings.ToLower(settings.Format)
+	}
+
+	if settings.Level != "" {
+		level, err := ParseLogLevel(settings.Level)
+		if err != nil {
+			return logConfig, err
+		}
+		logConfig.Level = level
+	}
+
+	if settings.PackageLevels != "" {
+		levels, err := ParsePackageLevels(settings.PackageLevels)
+		if err != nil {
+			return logConfig, err
+		}
+		logConfig.PackageLevels = levels
+	}
+
+	return logConfig, nil
+}
+
+// ParseLogLevel parses debug, info, warn/warning or error
+func ParseLogLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", value)
+}
+
+// ParsePackageLevels parses a comma-separated list of package=level pairs
+func ParsePackageLevels(value string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, levelName, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("expected package=level, got %q", pair)
+		}
+		level, err := ParseLogLevel(levelName)
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(name)] = level
+	}
+	return levels, nil
+}
+
+var (
+	backendMu     sync.RWMutex
+	backendLogger = newBackendLogger(DefaultLogConfig(), os.Stdout)
+	backendFormat = LogFormatText
+)
+
+// SetLogBackend replaces the logging backend, writing to w
+func SetLogBackend(config LogConfig, w io.Writer) {
+	logger := newBackendLogger(config, w)
+
+	backendMu.Lock()
+	defer backendMu.Unlock()
+	backendLogger = logger
+	backendFormat = config.Format
+}
+
+// Logger returns the slog logger used by the logging functions
+func Logger() *slog.Logger {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backendLogger
+}
+
+// isTextFormat reports whether the backend writes human readable lines
+func isTextFormat() bool {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backendFormat != LogFormatJSON
+}
+
+// newBackendLogger builds the slog logger for the given configuration
+func newBackendLogger(config LogConfig, w io.Writer) *slog.Logger {
+	minLevel := config.Level
+	for _, level := range config.PackageLevels {
+		if level < minLevel {
+			minLevel = level
+		}
+	}
+
+	options := &slog.HandlerOptions{AddSource: true, Level: minLevel}
+
+	var handler slog.Handler
+	if config.Format == LogFormatJSON {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+
+	return slog.New(&packageLevelHandler{
+		Handler:  handler,
+		level:    config.Level,
+		packages: config.PackageLevels,
+	})
+}
+
+// packageLevelHandler applies per-package levels based on the record's caller
+type packageLevelHandler struct {
+	slog.Handler
+	level    slog.Level
+	packages map[string]slog.Level
+}
+
+// Handle implements slog.Handler
+func (h *packageLevelHandler) Handle(ctx context.Context, record slog.Record) error {
+	level := h.level
+	if len(h.packages) > 0 {
+		if packageLevel, ok := h.packages[callerPackage(record.PC)]; ok {
+			level = packageLevel
+		}
+	}
+	if record.Level < level {
+		return nil
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *packageLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &packageLevelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level, packages: h.packages}
+}
+
+// WithGroup implements slog.Handler
+func (h *packageLevelHandler) WithGroup(name string) slog.Handler {
+	return &packageLevelHandler{Handler: h.Handler.WithGroup(name), level: h.level, packages: h.packages}
+}
+
+var callerPackages sync.Map // pc -> package name
+
+// callerPackage returns the short package name ("database") of the function at pc
+func callerPackage(pc uintptr) string {
+	if name, ok := callerPackages.Load(pc); ok {
+		return name.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	name := frame.Function
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	if dot := strings.Index(name, "."); dot >= 0 {
+		name = name[:dot]
+	}
+
+	callerPackages.Store(pc, name)
+	return name
+}
+
+// FieldLogger logs messages with a fixed set of structured key/value fields
+type FieldLogger struct {
+	fields []any
+}
+
+// With returns a logger that adds the given key/value pairs to every message
+func With(args ...any) *FieldLogger {
+	return &FieldLogger{fields: args}
+}
+
+// With returns a copy of the logger with additional key/value pairs
+func (l *FieldLogger) With(args ...any) *FieldLogger {
+	fields := make([]any, 0, len(l.fields)+len(args))
+	fields = append(append(fields, l.fields...), args...)
+	return &FieldLogger{fields: fields}
+}
+
+// Info logs an info level message
+func (l *FieldLogger) Info(format string, v ...interface{}) {
+	logMessage(slog.LevelInfo, "✨ ", fmt.Sprintf(format, v...), l.fields)
+}
+
+// Warning logs a warning level message
+func (l *FieldLogger) Warning(format string, v ...interface{}) {
+	logMessage(slog.LevelWarn, "⚠️ ", fmt.Sprintf(format, v...), l.fields)
+}
+
+// Error logs an error level message, adding the error as a field
+func (l *FieldLogger) Error(message string, err error) {
+	fields := l.fields
+	if err != nil {
+		fields = append(fields[:len(fields):len(fields)], "error", err.Error())
+	}
+	logMessage(slog.LevelError, "❌ ", message, fields)
+}
+
+// Success logs a success message at info level
+func (l *FieldLogger) Success(format string, v ...interface{}) {
+	logMessage(slog.LevelInfo, "✅ ", fmt.Sprintf(format, v...), l.fields)
+}
+
+// Debug logs a debug level message
+func (l *FieldLogger) Debug(format string, v ...interface{}) {
+	logMessage(slog.LevelDebug, "🐛 ", fmt.Sprintf(format, v...), l.fields)
+}
+
+// logMessage writes a redacted record attributed to the caller of the exported
+// logging function. The emoji prefix is only used by the text format.
+func logMessage(level slog.Level, prefix, message string, fields []any) {
+	logger := Logger()
+	ctx := context.Background()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	// Skip runtime.Callers, logMessage and the exported logging function
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	if isTextFormat() {
+		message = prefix + message
+	}
+
+	record := slog.NewRecord(time.Now(), level, RedactString(message), pcs[0])
+	record.Add(fields...)
+
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+
+	_ = logger.Handler().Handle(ctx, redacted)
+}
+
+// redactAttr masks secrets in string attribute values
+func redactAttr(attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindString {
+		attr.Value = slog.StringValue(RedactString(attr.Value.String()))
+	}
+	return attr
+}
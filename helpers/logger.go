@@ -1,67 +1,22 @@
 package helpers
 
 import (
+	"context"
 	"fmt"
-	"go-fiber-template/models"
-	"go-fiber-template/requests"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
-	"github.com/gofiber/fiber/v2/log"
-	"gorm.io/gorm"
+	"go-fiber-template/config"
 )
 
-// AsyncLogger handles database logging
-type AsyncLogger struct {
-	db      *gorm.DB
-	channel chan requests.LogEntry
-}
-
-// NewAsyncLogger creates a new async logger instance
-func NewAsyncLogger(db *gorm.DB) *AsyncLogger {
-	return &AsyncLogger{
-		db:      db,
-		channel: make(chan requests.LogEntry, 100), // Buffered channel
-	}
-}
-
-// ProcessLog processes log entries from the channel
-func (logger *AsyncLogger) ProcessLog() {
-	Info("🚀 Starting asynchronous logger...")
-
-	for logEntry := range logger.channel {
-		Debug(fmt.Sprintf("Processing log entry: %s %s", logEntry.Method, logEntry.URL))
-
-		dbLog := models.Log{
-			Method:          logEntry.Method,
-			URL:             logEntry.URL,
-			RequestBody:     logEntry.RequestBody,
-			ResponseBody:    logEntry.ResponseBody,
-			RequestHeaders:  logEntry.RequestHeaders,
-			ResponseHeaders: logEntry.ResponseHeaders,
-			StatusCode:      logEntry.StatusCode,
-			CreatedAt:       logEntry.CreatedAt,
-		}
-
-		if err := logger.db.Create(&dbLog).Error; err != nil {
-			Error(fmt.Sprintf("Failed to insert log entry: %v", err), nil)
-		} else {
-			Debug(fmt.Sprintf("Inserted log entry: %s %s", dbLog.Method, dbLog.URL))
-		}
-	}
-}
-
-// Log pushes a log entry into the channel
-func (logger *AsyncLogger) Log(entry requests.LogEntry) {
-	logger.channel <- entry
-}
-
 var (
 	logFile     *os.File
 	currentDate string
+	logClosed   bool
 	logMutex    sync.Mutex
 )
 
@@ -75,33 +30,129 @@ const (
 	DEBUG   LogLevel = "🐛 DEBUG"
 )
 
-// InitLogger initializes the logger
+// InitLogger initializes the logger with the application configuration
 func InitLogger() error {
+	logConfig, err := LogConfigFrom(config.Get().Log)
+	if err != nil {
+		return err
+	}
+	return InitLoggerWithConfig(logConfig)
+}
+
+// InitLoggerWithConfig initializes the logger with the given backend configuration
+func InitLoggerWithConfig(logConfig LogConfig) error {
+	logMutex.Lock()
+	if err := createLogsDirectory(); err != nil {
+		logMutex.Unlock()
+		return err
+	}
+
+	logClosed = false
+	if err := rotateLogFile(); err != nil {
+		logMutex.Unlock()
+		return err
+	}
+	logMutex.Unlock()
+
+	// Write to both the daily file and console
+	SetLogBackend(logConfig, io.MultiWriter(dailyLogWriter{}, os.Stdout))
+
+	OnShutdown(ShutdownPhaseLogging, "log file", func(ctx context.Context) error {
+		return CloseLogger()
+	})
+
+	if logsDir, err := LogsDir(); err == nil {
+		minFree := uint64(config.Get().Health.MinFreeDiskMB) * 1024 * 1024
+		RegisterHealthCheck("disk", DiskSpaceCheck(logsDir, minFree))
+	}
+	return nil
+}
+
+// SetConsoleWriter writes console log output to w instead of stdout, e.g. to keep
+// stdout for the output of a command
+func SetConsoleWriter(w io.Writer) error {
+	logConfig, err := LogConfigFrom(config.Get().Log)
+	if err != nil {
+		return err
+	}
+	SetLogBackend(logConfig, io.MultiWriter(dailyLogWriter{}, w))
+	return nil
+}
+
+// CloseLogger flushes and closes the current log file. Later messages are
+// only written to the console.
+func CloseLogger() error {
 	logMutex.Lock()
 	defer logMutex.Unlock()
 
-	if err := createLogsDirectory(); err != nil {
+	logClosed = true
+	if logFile == nil {
+		return nil
+	}
+
+	file := logFile
+	logFile = nil
+	currentDate = ""
+	if err := file.Sync(); err != nil {
+		file.Close()
 		return err
 	}
+	return file.Close()
+}
+
+// LogsDir returns the directory holding the daily log files
+func LogsDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logs"), nil
+}
+
+// CurrentLogFileName returns the name of the log file currently written to
+func CurrentLogFileName() string {
+	logMutex.Lock()
+	defer logMutex.Unlock()
 
-	return rotateLogFile()
+	if currentDate == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.log", currentDate)
 }
 
 // createLogsDirectory creates the logs directory if it doesn't exist
 func createLogsDirectory() error {
-	dir, err := os.Getwd()
+	logsDir, err := LogsDir()
 	if err != nil {
 		return err
 	}
 
-	logsDir := filepath.Join(dir, "logs")
 	if _, err := os.Stat(logsDir); os.IsNotExist(err) {
 		return os.MkdirAll(logsDir, 0755)
 	}
 	return nil
 }
 
-// rotateLogFile creates or rotates to a new log file for the current date
+// dailyLogWriter writes to the log file of the current day, rotating at midnight
+type dailyLogWriter struct{}
+
+// Write implements io.Writer
+func (dailyLogWriter) Write(p []byte) (int, error) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	// Discard file output once the logger is closed so the console still receives it
+	if logClosed {
+		return len(p), nil
+	}
+	if err := rotateLogFile(); err != nil {
+		return 0, err
+	}
+	return logFile.Write(p)
+}
+
+// rotateLogFile creates or rotates to a new log file for the current date.
+// Callers must hold logMutex.
 func rotateLogFile() error {
 	today := time.Now().Format("2006-01-02")
 	if today == currentDate && logFile != nil {
@@ -113,12 +164,12 @@ func rotateLogFile() error {
 		logFile.Close()
 	}
 
-	dir, err := os.Getwd()
+	logsDir, err := LogsDir()
 	if err != nil {
 		return err
 	}
 
-	logPath := filepath.Join(dir, "logs", fmt.Sprintf("%s.log", today))
+	logPath := filepath.Join(logsDir, fmt.Sprintf("%s.log", today))
 	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return err
@@ -126,44 +177,34 @@ func rotateLogFile() error {
 
 	logFile = file
 	currentDate = today
-
-	// Create a multi-writer that writes to both file and console
-	multiWriter := io.MultiWriter(file, os.Stdout)
-	log.SetOutput(multiWriter)
 	return nil
 }
 
 // Info logs an info level message
 func Info(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
-	log.Info("✨ " + message)
+	logMessage(slog.LevelInfo, "✨ ", fmt.Sprintf(format, v...), nil)
 }
 
 // Warning logs a warning level message
 func Warning(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
-	log.Warn("⚠️ " + message)
+	logMessage(slog.LevelWarn, "⚠️ ", fmt.Sprintf(format, v...), nil)
 }
 
-// Error logs an error level message
+// Error logs an error level message, adding the error as a field
 func Error(format string, err error) {
+	var fields []any
 	if err != nil {
-		log.Error("❌ " + format + ": " + err.Error())
-	} else {
-		log.Error("❌ " + format)
+		fields = []any{"error", err.Error()}
 	}
+	logMessage(slog.LevelError, "❌ ", format, fields)
 }
 
-// Success logs a success level message
+// Success logs a success message at info level
 func Success(format string, v ...interface{}) {
-	message := fmt.Sprintf(format, v...)
-	log.Info("✅ " + message)
+	logMessage(slog.LevelInfo, "✅ ", fmt.Sprintf(format, v...), nil)
 }
 
 // Debug logs a debug level message
 func Debug(format string, v ...interface{}) {
-	if os.Getenv("GO_ENV") != "production" {
-		message := fmt.Sprintf(format, v...)
-		log.Debug("🐛 " + message)
-	}
+	logMessage(slog.LevelDebug, "🐛 ", fmt.Sprintf(format, v...), nil)
 }
@@ -0,0 +1,407 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDurationBuckets are the histogram buckets, in seconds, used for latencies
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metric is anything that can write itself in the Prometheus text exposition format
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+// MetricsRegistry holds the metrics exposed by the /metrics endpoint
+type MetricsRegistry struct {
+	mu      sync.RWMutex
+	metrics map[string]metric
+}
+
+// NewMetricsRegistry creates a new, empty metrics registry
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{metrics: make(map[string]metric)}
+}
+
+var defaultMetricsRegistry = NewMetricsRegistry()
+
+// Metrics returns the application metrics registry
+func Metrics() *MetricsRegistry {
+	return defaultMetricsRegistry
+}
+
+// Application metrics
+var (
+	HTTPRequestsTotal = Metrics().Counter("http_requests_total",
+		"Total number of HTTP requests.", "method", "route", "status")
+	HTTPRequestDuration = Metrics().Histogram("http_request_duration_seconds",
+		"HTTP request latency in seconds.", DefaultDurationBuckets, "method", "route", "status")
+	DBQueryDuration = Metrics().Histogram("db_query_duration_seconds",
+		"Database query latency in seconds.", DefaultDurationBuckets, "operation", "table")
+	DBQueryErrorsTotal = Metrics().Counter("db_query_errors_total",
+		"Total number of failed database queries.", "operation", "table")
+	LoginAttemptsTotal = Metrics().Counter("auth_login_attempts_total",
+		"Total number of login attempts by result.", "result")
+	RateLimitedRequestsTotal = Metrics().Counter("rate_limited_requests_total",
+		"Total number of requests rejected by a rate limiter.", "limiter")
+)
+
+// register adds a metric, panicking on duplicate names as that is a programming error
+func (r *MetricsRegistry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.metrics[m.name()]; exists {
+		panic(fmt.Sprintf("metric %s is already registered", m.name()))
+	}
+	r.metrics[m.name()] = m
+}
+
+// Counter registers a new counter with the given label names
+func (r *MetricsRegistry) Counter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: newMetricDesc(name, help, labels), values: make(map[string]*counterSeries)}
+	r.register(c)
+	return c
+}
+
+// Histogram registers a new histogram with the given upper bounds and label names
+func (r *MetricsRegistry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	h := &HistogramVec{desc: newMetricDesc(name, help, labels), buckets: sorted, series: make(map[string]*histogramSeries)}
+	r.register(h)
+	return h
+}
+
+// GaugeFunc registers a gauge whose value is read from fn at scrape time
+func (r *MetricsRegistry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(&gaugeFunc{desc: newMetricDesc(name, help, nil), fn: fn, kind: "gauge"})
+}
+
+// CounterFunc registers a counter whose value is read from fn at scrape time
+func (r *MetricsRegistry) CounterFunc(name, help string, fn func() float64) {
+	r.register(&gaugeFunc{desc: newMetricDesc(name, help, nil), fn: fn, kind: "counter"})
+}
+
+// WriteText writes every metric in the Prometheus text exposition format, sorted by name
+func (r *MetricsRegistry) WriteText(w io.Writer) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metrics := make([]metric, len(names))
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mu.RUnlock()
+
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// metricDesc holds the name, help text and label names shared by every metric type
+type metricDesc struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func newMetricDesc(name, help string, labels []string) metricDesc {
+	return metricDesc{metricName: name, help: help, labels: labels}
+}
+
+func (d metricDesc) name() string {
+	return d.metricName
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric
+func (d metricDesc) writeHeader(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.metricName, escapeHelp(d.help), d.metricName, kind)
+}
+
+// seriesKey joins label values into a map key, checking their number
+func (d metricDesc) seriesKey(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.metricName, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// formatLabels renders {name="value",...} with optional extra pairs appended
+func (d metricDesc) formatLabels(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, value := range values {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, d.labels[i], escapeLabelValue(value)))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], escapeLabelValue(extra[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec is a monotonically increasing counter partitioned by labels
+type CounterVec struct {
+	desc   metricDesc
+	mu     sync.Mutex
+	values map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labels []string
+	value  float64
+}
+
+func (c *CounterVec) name() string {
+	return c.desc.name()
+}
+
+// Inc increments the counter for the given label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases the counter for the given label values; negative values are ignored
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	key := c.desc.seriesKey(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	series, ok := c.values[key]
+	if !ok {
+		series = &counterSeries{labels: append([]string{}, labelValues...)}
+		c.values[key] = series
+	}
+	series.value += delta
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.desc.writeHeader(w, "counter")
+	for _, key := range sortedKeys(c.values) {
+		series := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.desc.metricName, c.desc.formatLabels(series.labels), formatFloat(series.value))
+	}
+}
+
+// HistogramVec counts observations in buckets, partitioned by labels
+type HistogramVec struct {
+	desc    metricDesc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *HistogramVec) name() string {
+	return h.desc.name()
+}
+
+// Observe records a value for the given label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := h.desc.seriesKey(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{labels: append([]string{}, labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.series[key] = series
+	}
+
+	for i, upperBound := range h.buckets {
+		if value <= upperBound {
+			series.counts[i]++
+		}
+	}
+	series.sum += value
+	series.count++
+}
+
+// ObserveDuration records a duration in seconds for the given label values
+func (h *HistogramVec) ObserveDuration(d time.Duration, labelValues ...string) {
+	h.Observe(d.Seconds(), labelValues...)
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.desc.writeHeader(w, "histogram")
+	for _, key := range sortedKeys(h.series) {
+		series := h.series[key]
+		for i, upperBound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.desc.metricName,
+				h.desc.formatLabels(series.labels, "le", formatFloat(upperBound)), series.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.desc.metricName, h.desc.formatLabels(series.labels, "le", "+Inf"), series.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.desc.metricName, h.desc.formatLabels(series.labels), formatFloat(series.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.desc.metricName, h.desc.formatLabels(series.labels), series.count)
+	}
+}
+
+// gaugeFunc is an unlabeled metric read from a function at scrape time
+type gaugeFunc struct {
+	desc metricDesc
+	fn   func() float64
+	kind string
+}
+
+func (g *gaugeFunc) name() string {
+	return g.desc.name()
+}
+
+func (g *gaugeFunc) write(w io.Writer) {
+	g.desc.writeHeader(w, g.kind)
+	fmt.Fprintf(w, "%s %s\n", g.desc.metricName, formatFloat(g.fn()))
+}
+
+// RegisterDBMetrics records query durations through GORM callbacks and
+// exposes the connection pool statistics of db
+func RegisterDBMetrics(db *gorm.DB) error {
+	const startKey = "metrics:start"
+
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(startKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			value, ok := tx.InstanceGet(startKey)
+			if !ok {
+				return
+			}
+			start, ok := value.(time.Time)
+			if !ok {
+				return
+			}
+
+			table := tx.Statement.Table
+			if table == "" {
+				table = "(raw)"
+			}
+			DBQueryDuration.ObserveDuration(time.Since(start), operation, table)
+			if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+				DBQueryErrorsTotal.Inc(operation, table)
+			}
+		}
+	}
+
+	callbacks := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("gorm:create").Register, callbacks.Create().After("gorm:create").Register},
+		{"query", callbacks.Query().Before("gorm:query").Register, callbacks.Query().After("gorm:query").Register},
+		{"update", callbacks.Update().Before("gorm:update").Register, callbacks.Update().After("gorm:update").Register},
+		{"delete", callbacks.Delete().Before("gorm:delete").Register, callbacks.Delete().After("gorm:delete").Register},
+		{"row", callbacks.Row().Before("gorm:row").Register, callbacks.Row().After("gorm:row").Register},
+		{"raw", callbacks.Raw().Before("gorm:raw").Register, callbacks.Raw().After("gorm:raw").Register},
+	}
+	for _, registration := range registrations {
+		if err := registration.before("metrics:before_"+registration.operation, before); err != nil {
+			return err
+		}
+		if err := registration.after("metrics:after_"+registration.operation, after(registration.operation)); err != nil {
+			return err
+		}
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	registry := Metrics()
+	registry.GaugeFunc("db_pool_max_open_connections", "Maximum number of open connections to the database.",
+		func() float64 { return float64(sqlDB.Stats().MaxOpenConnections) })
+	registry.GaugeFunc("db_pool_open_connections", "Number of established connections, in use and idle.",
+		func() float64 { return float64(sqlDB.Stats().OpenConnections) })
+	registry.GaugeFunc("db_pool_in_use_connections", "Number of connections currently in use.",
+		func() float64 { return float64(sqlDB.Stats().InUse) })
+	registry.GaugeFunc("db_pool_idle_connections", "Number of idle connections.",
+		func() float64 { return float64(sqlDB.Stats().Idle) })
+	registry.CounterFunc("db_pool_wait_count_total", "Total number of connections waited for.",
+		func() float64 { return float64(sqlDB.Stats().WaitCount) })
+	registry.CounterFunc("db_pool_wait_duration_seconds_total", "Total time blocked waiting for a new connection.",
+		func() float64 { return sqlDB.Stats().WaitDuration.Seconds() })
+	return nil
+}
+
+// RegisterAsyncLoggerMetrics exposes the buffer usage and drop count of the async logger
+func RegisterAsyncLoggerMetrics(logger *AsyncLogger) {
+	registry := Metrics()
+	registry.GaugeFunc("async_logger_queue_depth", "Number of log entries waiting to be written.",
+		func() float64 { return float64(logger.QueueDepth()) })
+	registry.GaugeFunc("async_logger_queue_capacity", "Size of the log entry buffer.",
+		func() float64 { return float64(logger.QueueCapacity()) })
+	registry.CounterFunc("async_logger_dropped_total", "Total number of log entries dropped.",
+		func() float64 { return float64(logger.Dropped()) })
+}
+
+// sortedKeys returns the keys of a series map in a stable order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatFloat renders a sample value the way Prometheus expects
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func init() {
+	Metrics().GaugeFunc("process_uptime_seconds", "Time since the process started, in seconds.",
+		func() float64 { return Uptime().Seconds() })
+}
@@ -0,0 +1,339 @@
+package helpers
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-fiber-template/apperrors"
+	"go-fiber-template/config"
+	"go-fiber-template/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// rateLimitPurgeInterval is how often expired counters are removed from a store
+const rateLimitPurgeInterval = time.Minute
+
+// Rate limiting algorithms
+const (
+	// RateLimitFixedWindow counts hits in consecutive windows that reset at their boundary
+	RateLimitFixedWindow = "fixed"
+	// RateLimitSlidingWindow weights the previous window by how much of it still
+	// overlaps the last window duration, smoothing bursts at window boundaries
+	RateLimitSlidingWindow = "sliding"
+)
+
+// RateLimitStore counts hits per key and window. Implementations must be safe for concurrent use.
+type RateLimitStore interface {
+	// Increment adds a hit to the window of key starting at windowStart and returns
+	// the new count. The counter may be discarded once expiresAt has passed.
+	Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int64, error)
+	// Count returns the hits in the window of key starting at windowStart
+	Count(ctx context.Context, key string, windowStart time.Time) (int64, error)
+	// Reset removes every counter of key
+	Reset(ctx context.Context, key string) error
+}
+
+var rateLimitStore RateLimitStore
+
+// SetRateLimitStore sets the store shared by the auth rate limiters
+func SetRateLimitStore(store RateLimitStore) {
+	rateLimitStore = store
+}
+
+// GetRateLimitStore returns the store shared by the auth rate limiters
+func GetRateLimitStore() RateLimitStore {
+	return rateLimitStore
+}
+
+// NewRateLimitStoreFromConfig returns the store named by the configuration
+func NewRateLimitStoreFromConfig(db *gorm.DB, settings config.RateLimitConfig) RateLimitStore {
+	if settings.Store == "postgres" {
+		return NewPostgresRateLimitStore(db)
+	}
+	return NewMemoryRateLimitStore()
+}
+
+// RateLimitResult is the outcome of a rate limit check
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAt    time.Time
+	RetryAfter time.Duration
+}
+
+// RateLimiter allows up to limit hits per key within each window
+type RateLimiter struct {
+	store     RateLimitStore
+	name      string
+	limit     int
+	window    time.Duration
+	algorithm string
+}
+
+// NewRateLimiter creates a limiter whose keys are prefixed with name in the store
+func NewRateLimiter(store RateLimitStore, name string, limit int, window time.Duration, algorithm string) *RateLimiter {
+	return &RateLimiter{
+		store:     store,
+		name:      name,
+		limit:     limit,
+		window:    window,
+		algorithm: algorithm,
+	}
+}
+
+// Name returns the name the limiter reports in metrics and logs
+func (l *RateLimiter) Name() string {
+	return l.name
+}
+
+// Allow records a hit for key and reports whether it is within the limit.
+// Rejected hits are counted too, so clients that keep retrying stay blocked.
+func (l *RateLimiter) Allow(ctx context.Context, key string) (RateLimitResult, error) {
+	return l.allowAt(ctx, key, time.Now())
+}
+
+// allowAt records a hit for key at the given time
+func (l *RateLimiter) allowAt(ctx context.Context, key string, now time.Time) (RateLimitResult, error) {
+	windowStart := now.Truncate(l.window)
+	windowEnd := windowStart.Add(l.window)
+	storeKey := l.storeKey(key)
+
+	expiresAt := windowEnd
+	if l.algorithm == RateLimitSlidingWindow {
+		// The counter is still weighted while the next window is current
+		expiresAt = windowEnd.Add(l.window)
+	}
+
+	current, err := l.store.Increment(ctx, storeKey, windowStart, expiresAt)
+	if err != nil {
+		return RateLimitResult{Allowed: true, Limit: l.limit, Remaining: l.limit}, err
+	}
+
+	result := RateLimitResult{Limit: l.limit, ResetAt: windowEnd}
+	estimate := float64(current)
+	if l.algorithm == RateLimitSlidingWindow {
+		previous, err := l.store.Count(ctx, storeKey, windowStart.Add(-l.window))
+		if err != nil {
+			return RateLimitResult{Allowed: true, Limit: l.limit, Remaining: l.limit}, err
+		}
+		elapsed := now.Sub(windowStart)
+		weight := 1 - float64(elapsed)/float64(l.window)
+		estimate += float64(previous) * weight
+
+		if estimate > float64(l.limit) && current <= int64(l.limit) && previous > 0 {
+			// Wait until enough of the previous window has slid out
+			overlap := 1 - float64(int64(l.limit)-current)/float64(previous)
+			result.RetryAfter = time.Duration(overlap*float64(l.window)) - elapsed
+		}
+	}
+
+	result.Allowed = estimate <= float64(l.limit)
+	result.Remaining = max(l.limit-int(math.Ceil(estimate)), 0)
+	if !result.Allowed {
+		if result.RetryAfter <= 0 {
+			result.RetryAfter = windowEnd.Sub(now)
+		}
+		result.ResetAt = now.Add(result.RetryAfter)
+	}
+	return result, nil
+}
+
+// Reset clears the hits recorded for key
+func (l *RateLimiter) Reset(ctx context.Context, key string) error {
+	return l.store.Reset(ctx, l.storeKey(key))
+}
+
+// storeKey namespaces key by the limiter name so limiters can share a store
+func (l *RateLimiter) storeKey(key string) string {
+	return l.name + ":" + key
+}
+
+// LoginAccountLimiter returns the per-account login limiter from the configuration,
+// or nil when rate limiting is disabled or no store has been set
+func LoginAccountLimiter() *RateLimiter {
+	settings := config.Get().RateLimit
+	if !settings.Enabled || rateLimitStore == nil {
+		return nil
+	}
+	return NewRateLimiter(rateLimitStore, "login_account", settings.LoginAccountLimit,
+		settings.LoginAccountWindow, settings.Algorithm)
+}
+
+// LoginAccountKey normalizes a login email or phone number so every spelling of it shares one counter
+func LoginAccountKey(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// LoginLockoutDuration returns how long to lock an account after the given number of
+// consecutive failed logins, or 0 when it should not be locked. The account is locked
+// at every multiple of the threshold, doubling the duration each time up to the maximum.
+func LoginLockoutDuration(failedAttempts int) time.Duration {
+	settings := config.Get().RateLimit
+	threshold := settings.LockoutThreshold
+	if threshold <= 0 || failedAttempts < threshold || failedAttempts%threshold != 0 {
+		return 0
+	}
+
+	duration := settings.LockoutDuration
+	for i := 1; i < failedAttempts/threshold && duration < settings.LockoutMaxDuration; i++ {
+		duration *= 2
+	}
+	return min(duration, settings.LockoutMaxDuration)
+}
+
+// SetRateLimitHeaders adds the X-RateLimit-* headers describing result
+func SetRateLimitHeaders(c *fiber.Ctx, result RateLimitResult) {
+	c.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+}
+
+// RetryAfterSeconds formats a wait as a Retry-After header value of at least one second
+func RetryAfterSeconds(wait time.Duration) string {
+	return strconv.Itoa(max(int(math.Ceil(wait.Seconds())), 1))
+}
+
+// RateLimitError is returned when a rate limit rejects a request
+func RateLimitError(retryAfter time.Duration) *apperrors.Error {
+	return apperrors.TooManyRequests("Too many attempts, please try again later").
+		WithHeader(fiber.HeaderRetryAfter, RetryAfterSeconds(retryAfter))
+}
+
+// rateLimitCounterKey identifies a counter in the memory store
+type rateLimitCounterKey struct {
+	key         string
+	windowStart int64
+}
+
+// memoryRateLimitCounter is a counter held by the memory store
+type memoryRateLimitCounter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// MemoryRateLimitStore keeps counters in process memory, suitable for a single instance
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	counters  map[rateLimitCounterKey]*memoryRateLimitCounter
+	lastPurge time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		counters:  make(map[rateLimitCounterKey]*memoryRateLimitCounter),
+		lastPurge: time.Now(),
+	}
+}
+
+// Increment implements RateLimitStore
+func (s *MemoryRateLimitStore) Increment(_ context.Context, key string, windowStart, expiresAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPurge) >= rateLimitPurgeInterval {
+		for k, counter := range s.counters {
+			if !now.Before(counter.expiresAt) {
+				delete(s.counters, k)
+			}
+		}
+		s.lastPurge = now
+	}
+
+	k := rateLimitCounterKey{key: key, windowStart: windowStart.UnixNano()}
+	counter, ok := s.counters[k]
+	if !ok {
+		counter = &memoryRateLimitCounter{expiresAt: expiresAt}
+		s.counters[k] = counter
+	}
+	counter.count++
+	return counter.count, nil
+}
+
+// Count implements RateLimitStore
+func (s *MemoryRateLimitStore) Count(_ context.Context, key string, windowStart time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if counter, ok := s.counters[rateLimitCounterKey{key: key, windowStart: windowStart.UnixNano()}]; ok {
+		return counter.count, nil
+	}
+	return 0, nil
+}
+
+// Reset implements RateLimitStore
+func (s *MemoryRateLimitStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k := range s.counters {
+		if k.key == key {
+			delete(s.counters, k)
+		}
+	}
+	return nil
+}
+
+// PostgresRateLimitStore keeps counters in the rate_limit_counters table so every
+// instance shares them
+type PostgresRateLimitStore struct {
+	db        *gorm.DB
+	lastPurge atomic.Int64
+}
+
+// NewPostgresRateLimitStore creates a store backed by the database
+func NewPostgresRateLimitStore(db *gorm.DB) *PostgresRateLimitStore {
+	store := &PostgresRateLimitStore{db: db}
+	store.lastPurge.Store(time.Now().UnixNano())
+	return store
+}
+
+// Increment implements RateLimitStore with a single upsert, so concurrent hits are never lost
+func (s *PostgresRateLimitStore) Increment(ctx context.Context, key string, windowStart, expiresAt time.Time) (int64, error) {
+	s.purgeExpired(ctx)
+
+	var count int64
+	err := s.db.WithContext(ctx).Raw(`INSERT INTO rate_limit_counters (key, window_start, count, expires_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_counters.count + 1
+		RETURNING count`, key, windowStart.UTC(), expiresAt.UTC()).Scan(&count).Error
+	return count, err
+}
+
+// Count implements RateLimitStore
+func (s *PostgresRateLimitStore) Count(ctx context.Context, key string, windowStart time.Time) (int64, error) {
+	var counters []models.RateLimitCounter
+	err := s.db.WithContext(ctx).Where("key = ? AND window_start = ?", key, windowStart.UTC()).
+		Limit(1).Find(&counters).Error
+	if err != nil || len(counters) == 0 {
+		return 0, err
+	}
+	return counters[0].Count, nil
+}
+
+// Reset implements RateLimitStore
+func (s *PostgresRateLimitStore) Reset(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("key = ?", key).Delete(&models.RateLimitCounter{}).Error
+}
+
+// purgeExpired deletes expired counters at most once per purge interval across goroutines
+func (s *PostgresRateLimitStore) purgeExpired(ctx context.Context) {
+	now := time.Now()
+	last := s.lastPurge.Load()
+	if now.Sub(time.Unix(0, last)) < rateLimitPurgeInterval || !s.lastPurge.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	if err := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.RateLimitCounter{}).Error; err != nil {
+		WithContext(ctx).Warning("Failed to purge expired rate limit counters: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-template/config"
+)
+
+// failingRateLimitStore fails every operation
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Increment(context.Context, string, time.Time, time.Time) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func (failingRateLimitStore) Count(context.Context, string, time.Time) (int64, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func (failingRateLimitStore) Reset(context.Context, string) error {
+	return errors.New("store unavailable")
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	const limit = 10
+	window := time.Minute
+	windowStart := time.Now().Truncate(window)
+
+	tests := []struct {
+		name           string
+		algorithm      string
+		previousHits   int
+		elapsed        time.Duration
+		wantAllowed    int
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:           "fixed window ignores the previous window",
+			algorithm:      RateLimitFixedWindow,
+			previousHits:   limit,
+			elapsed:        30 * time.Second,
+			wantAllowed:    limit,
+			wantRetryAfter: 30 * time.Second,
+		},
+		{
+			name:           "sliding window without previous hits",
+			algorithm:      RateLimitSlidingWindow,
+			elapsed:        45 * time.Second,
+			wantAllowed:    limit,
+			wantRetryAfter: 15 * time.Second,
+		},
+		{
+			name:           "sliding window weighs half of the previous window",
+			algorithm:      RateLimitSlidingWindow,
+			previousHits:   limit,
+			elapsed:        30 * time.Second,
+			wantAllowed:    5,
+			wantRetryAfter: 6 * time.Second,
+		},
+		{
+			name:           "sliding window at the start of a window",
+			algorithm:      RateLimitSlidingWindow,
+			previousHits:   limit,
+			elapsed:        0,
+			wantAllowed:    0,
+			wantRetryAfter: 6 * time.Second,
+		},
+		{
+			name:           "sliding window with a partly used previous window",
+			algorithm:      RateLimitSlidingWindow,
+			previousHits:   4,
+			elapsed:        15 * time.Second,
+			wantAllowed:    7,
+			wantRetryAfter: 15 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(NewMemoryRateLimitStore(), "test", limit, window, tt.algorithm)
+			ctx := context.Background()
+			for i := 0; i < tt.previousHits; i++ {
+				if _, err := limiter.allowAt(ctx, "key", windowStart.Add(-window/2)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			now := windowStart.Add(tt.elapsed)
+			allowed := 0
+			var rejected RateLimitResult
+			for i := 0; i <= limit; i++ {
+				result, err := limiter.allowAt(ctx, "key", now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !result.Allowed {
+					rejected = result
+					break
+				}
+				allowed++
+				if want := tt.wantAllowed - allowed; result.Remaining != want {
+					t.Errorf("hit %d: Remaining = %d, want %d", allowed, result.Remaining, want)
+				}
+			}
+
+			if allowed != tt.wantAllowed {
+				t.Errorf("allowed %d hits, want %d", allowed, tt.wantAllowed)
+			}
+			if rejected.Remaining != 0 {
+				t.Errorf("rejected hit: Remaining = %d, want 0", rejected.Remaining)
+			}
+			if diff := rejected.RetryAfter - tt.wantRetryAfter; diff < -time.Millisecond || diff > time.Millisecond {
+				t.Errorf("RetryAfter = %s, want %s", rejected.RetryAfter, tt.wantRetryAfter)
+			}
+			if !rejected.ResetAt.Equal(now.Add(rejected.RetryAfter)) {
+				t.Errorf("ResetAt = %s, want now + RetryAfter", rejected.ResetAt)
+			}
+		})
+	}
+}
+
+func TestRateLimiterKeys(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	login := NewRateLimiter(store, "login", 1, time.Minute, RateLimitFixedWindow)
+	auth := NewRateLimiter(store, "auth", 1, time.Minute, RateLimitFixedWindow)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		limiter     *RateLimiter
+		key         string
+		wantAllowed bool
+	}{
+		{name: "first hit", limiter: login, key: "a", wantAllowed: true},
+		{name: "second hit", limiter: login, key: "a", wantAllowed: false},
+		{name: "rejected hits are counted", limiter: login, key: "a", wantAllowed: false},
+		{name: "other key", limiter: login, key: "b", wantAllowed: true},
+		{name: "other limiter sharing the store", limiter: auth, key: "a", wantAllowed: true},
+	}
+
+	for _, tt := range tests {
+		result, err := tt.limiter.Allow(ctx, tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != tt.wantAllowed {
+			t.Errorf("%s: Allowed = %v, want %v", tt.name, result.Allowed, tt.wantAllowed)
+		}
+	}
+
+	if err := login.Reset(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := login.Allow(ctx, "a"); !result.Allowed {
+		t.Error("hit after Reset is rejected")
+	}
+}
+
+func TestRateLimiterStoreFailureAllows(t *testing.T) {
+	for _, algorithm := range []string{RateLimitFixedWindow, RateLimitSlidingWindow} {
+		t.Run(algorithm, func(t *testing.T) {
+			limiter := NewRateLimiter(failingRateLimitStore{}, "test", 3, time.Minute, algorithm)
+			result, err := limiter.Allow(context.Background(), "key")
+			if err == nil || !result.Allowed || result.Remaining != 3 {
+				t.Errorf("Allow() = %+v, %v; want allowed with the store error", result, err)
+			}
+		})
+	}
+}
+
+func TestLoginLockoutDuration(t *testing.T) {
+	tests := []struct {
+		name           string
+		threshold      int
+		failedAttempts int
+		want           time.Duration
+	}{
+		{name: "no failures", threshold: 5, failedAttempts: 0, want: 0},
+		{name: "below the threshold", threshold: 5, failedAttempts: 4, want: 0},
+		{name: "at the threshold", threshold: 5, failedAttempts: 5, want: 15 * time.Minute},
+		{name: "between multiples", threshold: 5, failedAttempts: 7, want: 0},
+		{name: "second lockout doubles", threshold: 5, failedAttempts: 10, want: 30 * time.Minute},
+		{name: "third lockout reaches the maximum", threshold: 5, failedAttempts: 15, want: time.Hour},
+		{name: "capped at the maximum", threshold: 5, failedAttempts: 50, want: time.Hour},
+		{name: "threshold of one", threshold: 1, failedAttempts: 2, want: 30 * time.Minute},
+		{name: "lockout disabled", threshold: 0, failedAttempts: 5, want: 0},
+	}
+
+	previous := config.Get()
+	t.Cleanup(func() { config.Set(previous) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.RateLimit.LockoutThreshold = tt.threshold
+			cfg.RateLimit.LockoutDuration = 15 * time.Minute
+			cfg.RateLimit.LockoutMaxDuration = time.Hour
+			config.Set(cfg)
+
+			if got := LoginLockoutDuration(tt.failedAttempts); got != tt.want {
+				t.Errorf("LoginLockoutDuration(%d) = %s, want %s", tt.failedAttempts, got, tt.want)
+			}
+		})
+	}
+}
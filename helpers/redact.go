@@ -0,0 +1,200 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"go-fiber-template/config"
+)
+
+// RedactedValue replaces every secret removed by the redactor
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactJSONPaths are the JSON body rules always applied by NewRedactor
+var DefaultRedactJSONPaths = []string{"*password*", "*token*", "*secret*", "authorization", "cookie"}
+
+// DefaultRedactHeaders are the header names always redacted by NewRedactor
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+var (
+	// secretAssignmentPattern matches key=value / key: value pairs with secret-looking keys
+	secretAssignmentPattern = regexp.MustCompile(`(?i)([\w-]*(?:password|passwd|secret|token)[\w-]*"?\s*[=:]\s*"?)([^\s"&,;]+)`)
+	// authorizationPattern matches bearer and basic credentials
+	authorizationPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+)
+
+// Redactor masks secrets in captured request data before it is logged.
+//
+// JSON path rules are dot-separated, case-insensitive and support glob
+// segments ("user.*.password"). A rule with a single segment ("password")
+// matches the key at any depth. Arrays are transparent: "items.token" matches
+// the token key of every element of items.
+type Redactor struct {
+	jsonPaths [][]string
+	headers   map[string]bool
+}
+
+// NewRedactor creates a redactor with the default rules plus the given ones
+func NewRedactor(jsonPaths, headers []string) *Redactor {
+	redactor := &Redactor{headers: make(map[string]bool)}
+
+	for _, rule := range append(append([]string{}, DefaultRedactJSONPaths...), jsonPaths...) {
+		rule = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(rule)), "$.")
+		if rule != "" {
+			redactor.jsonPaths = append(redactor.jsonPaths, strings.Split(rule, "."))
+		}
+	}
+
+	for _, header := range append(append([]string{}, DefaultRedactHeaders...), headers...) {
+		if header = strings.TrimSpace(header); header != "" {
+			redactor.headers[strings.ToLower(header)] = true
+		}
+	}
+
+	return redactor
+}
+
+// NewRedactorFromConfig creates a redactor with the configured JSON path
+// and header rules added to the default rules
+func NewRedactorFromConfig(settings config.RedactConfig) *Redactor {
+	return NewRedactor(settings.JSONPaths, settings.Headers)
+}
+
+// RedactBody masks secrets in a request or response body based on its content type
+func (r *Redactor) RedactBody(body []byte, contentType string) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	contentType = strings.ToLower(contentType)
+	trimmed := bytes.TrimSpace(body)
+
+	if strings.Contains(contentType, "json") || bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("[")) {
+		if redacted, ok := r.RedactJSON(body); ok {
+			return redacted
+		}
+	}
+
+	if strings.Contains(contentType, "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			return []byte(r.redactValues(values).Encode())
+		}
+	}
+
+	return []byte(RedactString(string(body)))
+}
+
+// RedactJSON masks values matching the JSON path rules; ok is false if body is not JSON
+func (r *Redactor) RedactJSON(body []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, false
+	}
+
+	redacted, err := json.Marshal(r.redactJSONValue(data, nil))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// RedactHeaders masks the values of secret headers in place
+func (r *Redactor) RedactHeaders(headers map[string]string) map[string]string {
+	for name := range headers {
+		if r.headers[strings.ToLower(name)] {
+			headers[name] = RedactedValue
+		}
+	}
+	return headers
+}
+
+// RedactURL masks secret query parameters of a request URL
+func (r *Redactor) RedactURL(rawURL string) string {
+	pathPart, query, found := strings.Cut(rawURL, "?")
+	if !found {
+		return rawURL
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return RedactString(rawURL)
+	}
+	return pathPart + "?" + r.redactValues(values).Encode()
+}
+
+// redactValues masks form or query values whose keys match a single-segment rule
+func (r *Redactor) redactValues(values url.Values) url.Values {
+	for key := range values {
+		if r.matchesJSONPath([]string{strings.ToLower(key)}) {
+			values[key] = []string{RedactedValue}
+		}
+	}
+	return values
+}
+
+// redactJSONValue walks a decoded JSON value and masks matching keys
+func (r *Redactor) redactJSONValue(value interface{}, keyPath []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := append(keyPath[:len(keyPath):len(keyPath)], strings.ToLower(key))
+			if r.matchesJSONPath(childPath) {
+				v[key] = RedactedValue
+				continue
+			}
+			v[key] = r.redactJSONValue(child, childPath)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = r.redactJSONValue(child, keyPath)
+		}
+	}
+	return value
+}
+
+// matchesJSONPath reports whether a key path matches one of the JSON path rules
+func (r *Redactor) matchesJSONPath(keyPath []string) bool {
+	for _, rule := range r.jsonPaths {
+		if len(rule) == 1 {
+			if matchSegment(rule[0], keyPath[len(keyPath)-1]) {
+				return true
+			}
+			continue
+		}
+
+		if len(rule) != len(keyPath) {
+			continue
+		}
+
+		matched := true
+		for i, segment := range rule {
+			if !matchSegment(segment, keyPath[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegment matches a single lowercase key against a glob rule segment
+func matchSegment(pattern, key string) bool {
+	matched, err := path.Match(pattern, key)
+	return err == nil && matched
+}
+
+// RedactString masks secret-looking assignments and credentials in free text
+func RedactString(value string) string {
+	value = authorizationPattern.ReplaceAllString(value, "$1 "+RedactedValue)
+	return secretAssignmentPattern.ReplaceAllString(value, "${1}"+RedactedValue)
+}
@@ -0,0 +1,147 @@
+package helpers
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	redactor := NewRedactor([]string{"$.card.number", "user.*.pin", " "}, nil)
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+	}{
+		{
+			name:        "default rules at any depth",
+			body:        `{"email":"a@b.c","password":"hunter2","profile":{"refresh_token":"abc","name":"Jane"}}`,
+			contentType: "application/json",
+			want:        `{"email":"a@b.c","password":"[REDACTED]","profile":{"name":"Jane","refresh_token":"[REDACTED]"}}`,
+		},
+		{
+			name:        "keys are case-insensitive",
+			body:        `{"Authorization":"Bearer x","NewPassword":"y"}`,
+			contentType: "application/json",
+			want:        `{"Authorization":"[REDACTED]","NewPassword":"[REDACTED]"}`,
+		},
+		{
+			name:        "configured path only matches at its position",
+			body:        `{"card":{"number":"4111"},"number":"7"}`,
+			contentType: "application/json",
+			want:        `{"card":{"number":"[REDACTED]"},"number":"7"}`,
+		},
+		{
+			name:        "glob segment",
+			body:        `{"user":{"home":{"pin":"1234"},"pin":"5678"}}`,
+			contentType: "application/json",
+			want:        `{"user":{"home":{"pin":"[REDACTED]"},"pin":"5678"}}`,
+		},
+		{
+			name:        "arrays are transparent",
+			body:        `[{"token":"a"},{"token":"b","id":1}]`,
+			contentType: "application/json",
+			want:        `[{"token":"[REDACTED]"},{"id":1,"token":"[REDACTED]"}]`,
+		},
+		{
+			name:        "whole objects are redacted",
+			body:        `{"secrets":{"a":1}}`,
+			contentType: "application/json",
+			want:        `{"secrets":"[REDACTED]"}`,
+		},
+		{
+			name:        "JSON detected without content type",
+			body:        ` {"password":"x"}`,
+			contentType: "",
+			want:        `{"password":"[REDACTED]"}`,
+		},
+		{
+			name:        "invalid JSON falls back to text rules",
+			body:        `{"password": "hunter2", `,
+			contentType: "application/json",
+			want:        `{"password": "[REDACTED]", `,
+		},
+		{
+			name:        "form body",
+			body:        "email=a%40b.c&password=hunter2",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			want:        "email=a%40b.c&password=%5BREDACTED%5D",
+		},
+		{
+			name:        "plain text",
+			body:        "login with password=hunter2 and Authorization: Bearer abc.def",
+			contentType: "text/plain",
+			want:        "login with password=[REDACTED] and Authorization: Bearer [REDACTED]",
+		},
+		{
+			name: "empty body",
+			body: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redactor.RedactBody([]byte(tt.body), tt.contentType)); got != tt.want {
+				t.Errorf("RedactBody() = %s\nwant          %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		extra   []string
+		headers map[string]string
+		want    map[string]string
+	}{
+		{
+			name:    "default headers",
+			headers: map[string]string{"Authorization": "Bearer x", "Cookie": "a=b", "Accept": "*/*"},
+			want:    map[string]string{"Authorization": RedactedValue, "Cookie": RedactedValue, "Accept": "*/*"},
+		},
+		{
+			name:    "names are case-insensitive",
+			headers: map[string]string{"authorization": "Basic x", "SET-COOKIE": "a=b"},
+			want:    map[string]string{"authorization": RedactedValue, "SET-COOKIE": RedactedValue},
+		},
+		{
+			name:    "configured headers",
+			extra:   []string{" X-Api-Key ", ""},
+			headers: map[string]string{"x-api-key": "k", "X-Request-Id": "r"},
+			want:    map[string]string{"x-api-key": RedactedValue, "X-Request-Id": "r"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewRedactor(nil, tt.extra).RedactHeaders(tt.headers); !maps.Equal(got, tt.want) {
+				t.Errorf("RedactHeaders() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	redactor := NewRedactor(nil, nil)
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"/api/users", "/api/users"},
+		{"/api/invitations/accept?token=abc&lang=en", "/api/invitations/accept?lang=en&token=%5BREDACTED%5D"},
+		{"/api/logs?page=2", "/api/logs?page=2"},
+		{"/reset?password=a%zz", "/reset?password=[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := redactor.RedactURL(tt.url); got != tt.want {
+				t.Errorf("RedactURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
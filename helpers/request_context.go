@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestIDHeader is the header used to accept and echo request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDLocalsKey is the fiber.Ctx Locals key holding the request ID
+const RequestIDLocalsKey = "request_id"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// GetRequestID returns the ID of the current request set by middleware.RequestID
+func GetRequestID(c *fiber.Ctx) string {
+	requestID, _ := c.Locals(RequestIDLocalsKey).(string)
+	return requestID
+}
+
+// WithContext returns a logger that adds the request ID carried by ctx to every message
+func WithContext(ctx context.Context) *FieldLogger {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return With("request_id", requestID)
+	}
+	return With()
+}
+
+// WithRequest returns a logger that adds the current request ID to every message
+func WithRequest(c *fiber.Ctx) *FieldLogger {
+	if requestID := GetRequestID(c); requestID != "" {
+		return With("request_id", requestID)
+	}
+	return With()
+}
@@ -0,0 +1,343 @@
+package helpers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"go-fiber-template/config"
+	"go-fiber-template/models"
+
+	"gorm.io/gorm"
+)
+
+// dailyLogFilePattern matches daily log files, plain or compressed
+var dailyLogFilePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})\.log(\.gz)?$`)
+
+// RetentionConfig controls pruning of the logs table and the daily log files
+type RetentionConfig struct {
+	// DBMaxAge is how long rows are kept in the logs table; 0 disables pruning
+	DBMaxAge time.Duration
+	// DeleteBatchSize is the number of rows archived and deleted per batch
+	DeleteBatchSize int
+	// ArchiveRows exports rows to gzipped JSONL files in ArchiveDir before deleting them
+	ArchiveRows bool
+	ArchiveDir  string
+	// CompressFiles gzips daily log files of previous days
+	CompressFiles bool
+	// FileMaxAge is how long daily log files are kept; 0 keeps them forever
+	FileMaxAge time.Duration
+	// Interval is how often the retention job runs
+	Interval time.Duration
+}
+
+// RetentionConfigFrom builds the retention configuration from the application settings
+func RetentionConfigFrom(settings config.RetentionConfig) RetentionConfig {
+	return RetentionConfig{
+		DBMaxAge:        time.Duration(settings.DBRetentionDays) * 24 * time.Hour,
+		DeleteBatchSize: settings.BatchSize,
+		ArchiveRows:     settings.ArchiveEnabled,
+		ArchiveDir:      settings.ArchiveDir,
+		CompressFiles:   settings.CompressFiles,
+		FileMaxAge:      time.Duration(settings.FileRetentionDays) * 24 * time.Hour,
+		Interval:        settings.Interval,
+	}
+}
+
+// RetentionManager periodically archives and prunes old log rows and files
+type RetentionManager struct {
+	db       *gorm.DB
+	config   RetentionConfig
+	quit     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRetentionManager creates a new retention manager instance
+func NewRetentionManager(db *gorm.DB, config RetentionConfig) *RetentionManager {
+	if config.DeleteBatchSize <= 0 {
+		config.DeleteBatchSize = 1000
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+
+	return &RetentionManager{
+		db:     db,
+		config: config,
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start runs the retention job immediately and then every Interval in the background
+func (rm *RetentionManager) Start() {
+	go func() {
+		defer close(rm.done)
+
+		ticker := time.NewTicker(rm.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			rm.RunOnce()
+
+			select {
+			case <-ticker.C:
+			case <-rm.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background job and waits for a running pass to finish or ctx to be done
+func (rm *RetentionManager) Stop(ctx context.Context) error {
+	rm.stopOnce.Do(func() {
+		close(rm.quit)
+	})
+
+	select {
+	case <-rm.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce performs a single retention pass over the database and log files
+func (rm *RetentionManager) RunOnce() {
+	if rm.config.DBMaxAge > 0 {
+		if removed, err := rm.PruneDatabase(time.Now().Add(-rm.config.DBMaxAge)); err != nil {
+			Error("Failed to prune log records", err)
+		} else if removed > 0 {
+			Info("Removed %d log records older than %s", removed, rm.config.DBMaxAge)
+		}
+	}
+
+	if rm.config.CompressFiles {
+		if err := rm.CompressLogFiles(); err != nil {
+			Error("Failed to compress log files", err)
+		}
+	}
+
+	if rm.config.FileMaxAge > 0 {
+		if err := rm.DeleteOldLogFiles(time.Now().Add(-rm.config.FileMaxAge)); err != nil {
+			Error("Failed to delete old log files", err)
+		}
+	}
+}
+
+// PruneDatabase archives and deletes log rows created before cutoff in batches.
+// Each batch is only deleted once it has been written to the archive.
+func (rm *RetentionManager) PruneDatabase(cutoff time.Time) (int, error) {
+	var archive *jsonlArchive
+	if rm.config.ArchiveRows {
+		defer func() {
+			if archive != nil {
+				if err := archive.Close(); err != nil {
+					Error("Failed to close log archive", err)
+				}
+			}
+		}()
+	}
+
+	removed := 0
+	for {
+		select {
+		case <-rm.quit:
+			return removed, nil
+		default:
+		}
+
+		var rows []models.Log
+		err := rm.db.Unscoped().
+			Where("created_at < ?", cutoff).
+			Order("id ASC").
+			Limit(rm.config.DeleteBatchSize).
+			Find(&rows).Error
+		if err != nil {
+			return removed, err
+		}
+		if len(rows) == 0 {
+			return removed, nil
+		}
+
+		if rm.config.ArchiveRows {
+			if archive == nil {
+				if archive, err = newJSONLArchive(rm.config.ArchiveDir); err != nil {
+					return removed, err
+				}
+			}
+			if err := archive.Write(rows); err != nil {
+				return removed, err
+			}
+		}
+
+		ids := make([]uint, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+		}
+		if err := rm.db.Unscoped().Where("id IN ?", ids).Delete(&models.Log{}).Error; err != nil {
+			return removed, err
+		}
+		removed += len(rows)
+
+		if len(rows) < rm.config.DeleteBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+// CompressLogFiles gzips daily log files of previous days
+func (rm *RetentionManager) CompressLogFiles() error {
+	logsDir, err := LogsDir()
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		return err
+	}
+
+	current := CurrentLogFileName()
+	today := time.Now().Format("2006-01-02")
+	for _, entry := range entries {
+		match := dailyLogFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil || match[2] != "" {
+			continue
+		}
+		if match[1] >= today || entry.Name() == current {
+			continue
+		}
+
+		if err := gzipFile(filepath.Join(logsDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to compress %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// DeleteOldLogFiles removes daily log files dated before cutoff
+func (rm *RetentionManager) DeleteOldLogFiles(cutoff time.Time) error {
+	logsDir, err := LogsDir()
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		return err
+	}
+
+	current := CurrentLogFileName()
+	cutoffDate := cutoff.Format("2006-01-02")
+	for _, entry := range entries {
+		match := dailyLogFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil || entry.Name() == current {
+			continue
+		}
+		if match[1] < cutoffDate {
+			if err := os.Remove(filepath.Join(logsDir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gzipFile compresses path into path.gz and removes the original
+func gzipFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	tmpPath := path + ".gz.tmp"
+	target, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+	if _, err := io.Copy(writer, source); err != nil {
+		target.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		target.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := target.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// jsonlArchive writes archived log rows as gzipped JSON lines
+type jsonlArchive struct {
+	file   *os.File
+	gzip   *gzip.Writer
+	writer *bufio.Writer
+}
+
+// newJSONLArchive creates a new archive file named after the current time
+func newJSONLArchive(dir string) (*jsonlArchive, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("logs-%s.jsonl.gz", time.Now().Format("20060102-150405"))
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(file)
+	return &jsonlArchive{file: file, gzip: gz, writer: bufio.NewWriter(gz)}, nil
+}
+
+// Write appends the rows and flushes them to disk
+func (a *jsonlArchive) Write(rows []models.Log) error {
+	encoder := json.NewEncoder(a.writer)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+
+	if err := a.writer.Flush(); err != nil {
+		return err
+	}
+	if err := a.gzip.Flush(); err != nil {
+		return err
+	}
+	return a.file.Sync()
+}
+
+// Close finishes the gzip stream and closes the file
+func (a *jsonlArchive) Close() error {
+	if err := a.writer.Flush(); err != nil {
+		return err
+	}
+	if err := a.gzip.Close(); err != nil {
+		return err
+	}
+	return a.file.Close()
+}
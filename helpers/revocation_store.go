@@ -0,0 +1,261 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-fiber-template/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revocationCheckTTL is how long a "not revoked" lookup is trusted before the
+// database is consulted again, so revocations made by other instances apply.
+const revocationCheckTTL = 30 * time.Second
+
+// revocationCheckLimit bounds the cached "not revoked" lookups. Expired entries are
+// swept when it is reached, and the cache is cleared if it is still full.
+const revocationCheckLimit = 100000
+
+// revocationPurgeInterval is how often expired revocations are deleted
+const revocationPurgeInterval = 10 * time.Minute
+
+// RevocationStore keeps revoked access tokens in the database with an
+// in-memory cache consulted on every authenticated request
+type RevocationStore struct {
+	db          *gorm.DB
+	mu          sync.RWMutex
+	tokens      map[string]time.Time // jti -> token expiry
+	userCutoffs map[uint]time.Time   // user ID -> tokens issued up to this time are revoked
+	checked     map[string]time.Time // jti -> negative lookup valid until
+	quit        chan struct{}
+	done        chan struct{}
+	stopOnce    sync.Once
+}
+
+var revocationStore *RevocationStore
+
+// NewRevocationStore creates a new revocation store instance
+func NewRevocationStore(db *gorm.DB) *RevocationStore {
+	return &RevocationStore{
+		db:          db,
+		tokens:      make(map[string]time.Time),
+		userCutoffs: make(map[uint]time.Time),
+		checked:     make(map[string]time.Time),
+		quit:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// SetRevocationStore sets the store used by the authentication middleware
+func SetRevocationStore(store *RevocationStore) {
+	revocationStore = store
+}
+
+// GetRevocationStore returns the store used by the authentication middleware
+func GetRevocationStore() *RevocationStore {
+	return revocationStore
+}
+
+// Load fills the cache with every revocation that has not expired yet
+func (s *RevocationStore) Load() error {
+	var rows []models.RevokedToken
+	if err := s.db.Where("expires_at > ?", time.Now()).Find(&rows).Error; err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		s.cacheRow(row)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token with the given jti, owner and issue time is revoked
+func (s *RevocationStore) IsRevoked(ctx context.Context, jti string, userID uint, issuedAt time.Time) (bool, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	revoked := s.isRevokedLocked(jti, userID, issuedAt, now)
+	checkedUntil, checked := s.checked[jti]
+	s.mu.RUnlock()
+
+	if revoked {
+		return true, nil
+	}
+	if checked && now.Before(checkedUntil) {
+		return false, nil
+	}
+
+	var rows []models.RevokedToken
+	err := s.db.WithContext(ctx).Where("jti IN ? AND expires_at > ?", []string{jti, userRevocationKey(userID)}, now).
+		Find(&rows).Error
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		s.cacheRow(row)
+	}
+	if len(s.checked) >= revocationCheckLimit {
+		s.pruneCheckedLocked(now)
+		if len(s.checked) >= revocationCheckLimit {
+			clear(s.checked)
+		}
+	}
+	s.checked[jti] = now.Add(revocationCheckTTL)
+	return s.isRevokedLocked(jti, userID, issuedAt, now), nil
+}
+
+// RevokeToken revokes a single access token until it expires
+func (s *RevocationStore) RevokeToken(ctx context.Context, jti string, userID uint, expiresAt time.Time) error {
+	row := models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cacheRow(row)
+	s.purgeLocked(time.Now())
+	return nil
+}
+
+// RevokeAllForUser revokes every access token of a user issued up to now.
+// Tokens are compared by their nanosecond iat_ns claim, so a token issued right after
+// the call stays valid. The cutoff is rounded up to the microsecond precision of the
+// database so every instance compares against the same value; a token issued in the
+// same microsecond after the call is revoked too.
+func (s *RevocationStore) RevokeAllForUser(ctx context.Context, userID uint) error {
+	now := time.Now()
+	cutoff := now.Truncate(time.Microsecond).Add(time.Microsecond)
+	row := models.RevokedToken{
+		JTI:          userRevocationKey(userID),
+		UserID:       userID,
+		IssuedBefore: &cutoff,
+		ExpiresAt:    now.Add(AccessTokenTTL()),
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "jti"}},
+		DoUpdates: clause.AssignmentColumns([]string{"issued_before", "expires_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cacheRow(row)
+	s.purgeLocked(now)
+	return nil
+}
+
+// PurgeExpired removes revocations for tokens that have expired anyway
+func (s *RevocationStore) PurgeExpired() error {
+	now := time.Now()
+	if err := s.db.Where("expires_at <= ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purgeLocked(now)
+	return nil
+}
+
+// Start deletes expired revocations every revocationPurgeInterval in the background
+func (s *RevocationStore) Start() {
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(revocationPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.PurgeExpired(); err != nil {
+					Error("Failed to purge expired revoked tokens", err)
+				}
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background purge and waits for a running pass to finish or ctx to be done
+func (s *RevocationStore) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.quit)
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cacheRow adds a revocation row to the cache; callers must hold the write lock
+func (s *RevocationStore) cacheRow(row models.RevokedToken) {
+	if row.IssuedBefore != nil {
+		if cutoff, ok := s.userCutoffs[row.UserID]; !ok || row.IssuedBefore.After(cutoff) {
+			s.userCutoffs[row.UserID] = *row.IssuedBefore
+		}
+		return
+	}
+	s.tokens[row.JTI] = row.ExpiresAt
+}
+
+// isRevokedLocked checks the cache; callers must hold at least the read lock
+func (s *RevocationStore) isRevokedLocked(jti string, userID uint, issuedAt, now time.Time) bool {
+	if expiresAt, ok := s.tokens[jti]; ok && now.Before(expiresAt) {
+		return true
+	}
+	if cutoff, ok := s.userCutoffs[userID]; ok && issuedAt.Before(cutoff) {
+		return true
+	}
+	return false
+}
+
+// purgeLocked drops expired cache entries; callers must hold the write lock
+func (s *RevocationStore) purgeLocked(now time.Time) {
+	for jti, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, jti)
+		}
+	}
+	for userID, cutoff := range s.userCutoffs {
+		// Every token issued before the cutoff has expired by now
+		if now.After(cutoff.Add(AccessTokenTTL())) {
+			delete(s.userCutoffs, userID)
+		}
+	}
+	s.pruneCheckedLocked(now)
+}
+
+// pruneCheckedLocked drops expired negative lookups; callers must hold the write lock
+func (s *RevocationStore) pruneCheckedLocked(now time.Time) {
+	for jti, until := range s.checked {
+		if !now.Before(until) {
+			delete(s.checked, jti)
+		}
+	}
+}
+
+// userRevocationKey returns the jti used to store a user-wide revocation
+func userRevocationKey(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
@@ -0,0 +1,225 @@
+package helpers
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-template/testutil"
+)
+
+// revocationRows answers revoked_tokens lookups with the given rows of jti, user_id,
+// issued_before and expires_at
+func revocationRows(rows ...[]driver.Value) testutil.Handler {
+	return func(testutil.Query) testutil.Result {
+		return testutil.Result{Columns: []string{"jti", "user_id", "issued_before", "expires_at"}, Rows: rows}
+	}
+}
+
+func TestRevocationStoreIsRevoked(t *testing.T) {
+	now := time.Now()
+	issuedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		handler     testutil.Handler
+		setup       func(s *RevocationStore)
+		jti         string
+		wantRevoked bool
+		wantErr     bool
+		wantQueries int
+	}{
+		{
+			name:        "cached token",
+			setup:       func(s *RevocationStore) { s.tokens["revoked"] = now.Add(time.Hour) },
+			jti:         "revoked",
+			wantRevoked: true,
+		},
+		{
+			name:        "cached token past expiry is looked up",
+			setup:       func(s *RevocationStore) { s.tokens["revoked"] = now.Add(-time.Second) },
+			jti:         "revoked",
+			wantQueries: 1,
+		},
+		{
+			name:        "issued before the cached user cutoff",
+			setup:       func(s *RevocationStore) { s.userCutoffs[1] = issuedAt.Add(time.Nanosecond) },
+			jti:         "other",
+			wantRevoked: true,
+		},
+		{
+			name:        "issued at the cached user cutoff is looked up",
+			setup:       func(s *RevocationStore) { s.userCutoffs[1] = issuedAt },
+			jti:         "other",
+			wantQueries: 1,
+		},
+		{
+			name:        "negative lookup is cached",
+			setup:       func(s *RevocationStore) { s.checked["token"] = now.Add(time.Second) },
+			jti:         "token",
+			wantQueries: 0,
+		},
+		{
+			name:        "expired negative lookup is looked up again",
+			setup:       func(s *RevocationStore) { s.checked["token"] = now.Add(-time.Second) },
+			jti:         "token",
+			wantQueries: 1,
+		},
+		{
+			name:        "revoked by another instance",
+			handler:     revocationRows([]driver.Value{"token", int64(1), nil, now.Add(time.Hour)}),
+			jti:         "token",
+			wantRevoked: true,
+			wantQueries: 1,
+		},
+		{
+			name:        "user revoked by another instance",
+			handler:     revocationRows([]driver.Value{"user:1", int64(1), now, now.Add(time.Hour)}),
+			jti:         "token",
+			wantRevoked: true,
+			wantQueries: 1,
+		},
+		{
+			name:        "database error",
+			handler:     func(testutil.Query) testutil.Result { return testutil.Result{Err: errors.New("connection lost")} },
+			jti:         "token",
+			wantErr:     true,
+			wantQueries: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewDB(t, tt.handler)
+			store := NewRevocationStore(db.DB)
+			if tt.setup != nil {
+				tt.setup(store)
+			}
+
+			revoked, err := store.IsRevoked(context.Background(), tt.jti, 1, issuedAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IsRevoked() error = %v, want error %v", err, tt.wantErr)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("IsRevoked() = %v, want %v", revoked, tt.wantRevoked)
+			}
+			if got := len(db.Queries()); got != tt.wantQueries {
+				t.Errorf("database queried %d times, want %d", got, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestRevocationStoreCachesLookups(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     testutil.Handler
+		wantRevoked bool
+	}{
+		{name: "not revoked", wantRevoked: false},
+		{
+			name:        "revoked",
+			handler:     revocationRows([]driver.Value{"token", int64(1), nil, time.Now().Add(time.Hour)}),
+			wantRevoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewDB(t, tt.handler)
+			store := NewRevocationStore(db.DB)
+
+			for i := 0; i < 3; i++ {
+				revoked, err := store.IsRevoked(context.Background(), "token", 1, time.Now())
+				if err != nil {
+					t.Fatalf("IsRevoked() error = %v", err)
+				}
+				if revoked != tt.wantRevoked {
+					t.Fatalf("IsRevoked() = %v, want %v", revoked, tt.wantRevoked)
+				}
+			}
+			if got := len(db.Queries()); got != 1 {
+				t.Errorf("database queried %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestRevocationStoreRevokeOverridesNegativeCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		revoke func(s *RevocationStore) error
+	}{
+		{
+			name: "token",
+			revoke: func(s *RevocationStore) error {
+				return s.RevokeToken(context.Background(), "token", 1, time.Now().Add(time.Hour))
+			},
+		},
+		{
+			name: "every token of the user",
+			revoke: func(s *RevocationStore) error {
+				return s.RevokeAllForUser(context.Background(), 1)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testutil.NewDB(t, nil)
+			store := NewRevocationStore(db.DB)
+			issuedAt := time.Now()
+
+			if revoked, _ := store.IsRevoked(context.Background(), "token", 1, issuedAt); revoked {
+				t.Fatal("token is revoked before the revocation")
+			}
+			if err := tt.revoke(store); err != nil {
+				t.Fatalf("revoke error = %v", err)
+			}
+			if revoked, _ := store.IsRevoked(context.Background(), "token", 1, issuedAt); !revoked {
+				t.Error("token is not revoked after the revocation")
+			}
+		})
+	}
+}
+
+func TestRevocationStoreRevokeAllForUserCutoff(t *testing.T) {
+	db := testutil.NewDB(t, nil)
+	store := NewRevocationStore(db.DB)
+
+	before := time.Now()
+	if err := store.RevokeAllForUser(context.Background(), 1); err != nil {
+		t.Fatalf("RevokeAllForUser() error = %v", err)
+	}
+	cutoff := store.userCutoffs[1]
+
+	tests := []struct {
+		name        string
+		userID      uint
+		issuedAt    time.Time
+		wantRevoked bool
+	}{
+		{name: "issued before the call", userID: 1, issuedAt: before, wantRevoked: true},
+		{name: "issued at the cutoff", userID: 1, issuedAt: cutoff, wantRevoked: false},
+		{name: "other user", userID: 2, issuedAt: before, wantRevoked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.mu.RLock()
+			defer store.mu.RUnlock()
+			if got := store.isRevokedLocked("token", tt.userID, tt.issuedAt, time.Now()); got != tt.wantRevoked {
+				t.Errorf("revoked = %v, want %v", got, tt.wantRevoked)
+			}
+		})
+	}
+
+	if !cutoff.After(before) || cutoff.Sub(time.Now()) > time.Microsecond {
+		t.Errorf("cutoff %v is not within a microsecond after the call", cutoff)
+	}
+	if cutoff.Nanosecond()%int(time.Microsecond) != 0 {
+		t.Errorf("cutoff %v is not rounded to the microsecond", cutoff)
+	}
+}
@@ -0,0 +1,145 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShutdownPhase orders shutdown hooks; phases run from the lowest to the highest value
+type ShutdownPhase int
+
+const (
+	// ShutdownPhaseHTTP stops accepting connections and waits for in-flight requests
+	ShutdownPhaseHTTP ShutdownPhase = iota
+	// ShutdownPhaseWorkers drains background workers such as the asynchronous logger
+	ShutdownPhaseWorkers
+	// ShutdownPhaseDatabase releases database connections
+	ShutdownPhaseDatabase
+	// ShutdownPhaseLogging flushes and closes the log files
+	ShutdownPhaseLogging
+)
+
+// String returns the name of the phase
+func (p ShutdownPhase) String() string {
+	switch p {
+	case ShutdownPhaseHTTP:
+		return "http"
+	case ShutdownPhaseWorkers:
+		return "workers"
+	case ShutdownPhaseDatabase:
+		return "database"
+	case ShutdownPhaseLogging:
+		return "logging"
+	default:
+		return fmt.Sprintf("phase-%d", int(p))
+	}
+}
+
+// ShutdownHook releases a resource, giving up when ctx is done
+type ShutdownHook func(ctx context.Context) error
+
+type shutdownHook struct {
+	name  string
+	phase ShutdownPhase
+	fn    ShutdownHook
+}
+
+// ShutdownManager runs registered hooks phase by phase when the process stops
+type ShutdownManager struct {
+	mu           sync.Mutex
+	hooks        []shutdownHook
+	shuttingDown atomic.Bool
+	once         sync.Once
+	err          error
+}
+
+// NewShutdownManager creates a new shutdown manager instance
+func NewShutdownManager() *ShutdownManager {
+	return &ShutdownManager{}
+}
+
+var defaultShutdownManager = NewShutdownManager()
+
+// OnShutdown registers a hook with the application shutdown manager
+func OnShutdown(phase ShutdownPhase, name string, hook ShutdownHook) {
+	defaultShutdownManager.Register(phase, name, hook)
+}
+
+// Shutdown runs the hooks of the application shutdown manager
+func Shutdown(phaseTimeout time.Duration) error {
+	return defaultShutdownManager.Shutdown(phaseTimeout)
+}
+
+// IsShuttingDown reports whether the application has started shutting down
+func IsShuttingDown() bool {
+	return defaultShutdownManager.IsShuttingDown()
+}
+
+// Register adds a hook to a phase. Hooks of the same phase run in registration order.
+func (m *ShutdownManager) Register(phase ShutdownPhase, name string, hook ShutdownHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, shutdownHook{name: name, phase: phase, fn: hook})
+}
+
+// IsShuttingDown reports whether Shutdown has been called
+func (m *ShutdownManager) IsShuttingDown() bool {
+	return m.shuttingDown.Load()
+}
+
+// Shutdown runs every hook once, phase by phase, giving each phase up to
+// phaseTimeout. A failing hook does not stop the remaining ones; all errors
+// are returned together. Later calls return the result of the first one.
+func (m *ShutdownManager) Shutdown(phaseTimeout time.Duration) error {
+	m.once.Do(func() {
+		m.shuttingDown.Store(true)
+
+		m.mu.Lock()
+		phases := make(map[ShutdownPhase][]shutdownHook)
+		var order []ShutdownPhase
+		for _, hook := range m.hooks {
+			if _, ok := phases[hook.phase]; !ok {
+				order = append(order, hook.phase)
+			}
+			phases[hook.phase] = append(phases[hook.phase], hook)
+		}
+		m.mu.Unlock()
+
+		sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+		var errs []error
+		for _, phase := range order {
+			errs = append(errs, runShutdownPhase(phase, phases[phase], phaseTimeout)...)
+		}
+		m.err = errors.Join(errs...)
+	})
+	return m.err
+}
+
+// runShutdownPhase runs the hooks of one phase under a shared deadline
+func runShutdownPhase(phase ShutdownPhase, hooks []shutdownHook, timeout time.Duration) []error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for _, hook := range hooks {
+		start := time.Now()
+		if err := hook.fn(ctx); err != nil {
+			err = fmt.Errorf("%s shutdown hook %q: %w", phase, hook.name, err)
+			if phase < ShutdownPhaseLogging {
+				Error("Shutdown hook failed", err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if phase < ShutdownPhaseLogging {
+			Debug("Shutdown hook %q (%s) finished in %s", hook.name, phase, time.Since(start))
+		}
+	}
+	return errs
+}
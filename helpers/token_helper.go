@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"go-fiber-template/config"
+)
+
+// AccessTokenTTL returns the lifetime of access tokens
+func AccessTokenTTL() time.Duration {
+	return config.Get().Auth.AccessTokenTTL
+}
+
+// RefreshTokenTTL returns the lifetime of refresh tokens
+func RefreshTokenTTL() time.Duration {
+	return config.Get().Auth.RefreshTokenTTL
+}
+
+// InvitationTTL returns the lifetime of invitation tokens
+func InvitationTTL() time.Duration {
+	return config.Get().Auth.InvitationTTL
+}
+
+// GenerateRefreshToken returns a new opaque refresh token and its hash.
+// Only the hash is meant to be persisted.
+func GenerateRefreshToken() (string, string, error) {
+	return GenerateOpaqueToken()
+}
+
+// GenerateOpaqueToken returns a new random URL-safe token and its hash
+func GenerateOpaqueToken() (string, string, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	return token, HashToken(token), nil
+}
+
+// GenerateTokenFamily returns a new identifier for a refresh token family
+func GenerateTokenFamily() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken returns the SHA-256 hex digest of an opaque token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString returns a URL-safe random string built from n random bytes
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -1,31 +1,62 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"go-fiber-template/config"
 	"go-fiber-template/database"
 	"go-fiber-template/helpers"
 	"go-fiber-template/middleware"
 	"go-fiber-template/routes"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	// Load environment variables from .env
-	if err := godotenv.Load(); err != nil {
-		helpers.Warning("⚠️  Could not load .env file, using system environment variables")
+	// Load the configuration from .env, the environment and the optional YAML file
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌ Could not load configuration:", err)
+		os.Exit(1)
 	}
+	config.Set(cfg)
 
 	// Initialize logger
 	if err := helpers.InitLogger(); err != nil {
 		helpers.Error("❌ Could not initialize logger: %v", err)
 		return
 	}
+	migrating := len(os.Args) > 1 && os.Args[1] == "migrate"
+	if migrating {
+		// Keep stdout for the output of the migrate command
+		if err := helpers.SetConsoleWriter(os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	helpers.Debug("Loaded configuration:\n%s", cfg)
+
+	// Manage the schema instead of serving when run as "migrate ..."
+	if migrating {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(migrateExitCode(err))
+		}
+		return
+	}
+
+	// Run the registered shutdown hooks in order whenever main returns
+	defer func() {
+		if err := helpers.Shutdown(cfg.App.ShutdownTimeout); err != nil {
+			fmt.Fprintln(os.Stderr, "Shutdown completed with errors:", err)
+		}
+	}()
 
 	// Create Fiber app with config
 	app := fiber.New(fiber.Config{
@@ -34,23 +65,27 @@ func main() {
 		ReadTimeout:     30 * time.Second, // Read timeout
 		WriteTimeout:    30 * time.Second, // Write timeout
 		BodyLimit:       50 * 1024 * 1024, // 50 MB
+		ErrorHandler:    helpers.ErrorHandler,
 	})
 
+	// Assign a request ID before anything else logs
+	app.Use(middleware.RequestID())
+
+	// Keep a panic in any later middleware from killing the process
+	app.Use(middleware.Recover())
+
 	// Setup CORS
-	frontendURL := os.Getenv("FRONTEND_URL")
-	if frontendURL == "" {
-		frontendURL = "http://localhost:3000"
-		helpers.Warning("FRONTEND_URL not set. Defaulting to: %s", frontendURL)
-	}
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     frontendURL,
+		AllowOrigins:     cfg.App.FrontendURL,
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Request-ID",
+		ExposeHeaders:    "X-Request-ID",
 		AllowCredentials: true, // Required if using cookies or Authorization headers
 	}))
 
 	// Middleware
 	app.Use(middleware.RequestLogger())
+	app.Use(middleware.Metrics())
 
 	// Connect to the database
 	db, err := database.ConnectDB()
@@ -59,13 +94,41 @@ func main() {
 		return
 	}
 
+	// Start the asynchronous database logger and persist request audit records
+	asyncLogger := helpers.NewAsyncLogger(db)
+	go asyncLogger.ProcessLog()
+	helpers.OnShutdown(helpers.ShutdownPhaseWorkers, "async logger", asyncLogger.Shutdown)
+	helpers.RegisterHealthCheck("async_logger", asyncLogger.HealthCheck(cfg.Health.MaxLogQueueRatio))
+	helpers.RegisterAsyncLoggerMetrics(asyncLogger)
+	app.Use(middleware.AuditLogger(asyncLogger, middleware.AuditConfigFrom(cfg.Audit, cfg.Redact)))
+
+	// Turn panics in handlers into errors inside the audit middleware as well, so
+	// requests that end in a panic are audited with their 500 response
+	app.Use(middleware.Recover())
+
+	// Archive and prune old log records and daily log files
+	retentionManager := helpers.NewRetentionManager(db, helpers.RetentionConfigFrom(cfg.Retention))
+	retentionManager.Start()
+	helpers.OnShutdown(helpers.ShutdownPhaseWorkers, "log retention", retentionManager.Stop)
+
+	// Load revoked access tokens checked by the auth middleware
+	revocationStore := helpers.NewRevocationStore(db)
+	if err := revocationStore.Load(); err != nil {
+		helpers.Error("Could not load revoked tokens", err)
+		return
+	}
+	helpers.SetRevocationStore(revocationStore)
+	revocationStore.Start()
+	helpers.OnShutdown(helpers.ShutdownPhaseWorkers, "revocation purge", revocationStore.Stop)
+
+	// Share auth rate limit counters through the configured store
+	helpers.SetRateLimitStore(helpers.NewRateLimitStoreFromConfig(db, cfg.RateLimit))
+
 	// Setup routes
 	routes.SetupRoutes(app, db)
 
 	// Server start logs
-	appHost := os.Getenv("APP_HOST")
-	appPort := os.Getenv("APP_PORT")
-	serverAddress := appHost + ":" + appPort
+	serverAddress := cfg.App.Address()
 
 	// Print to console directly for immediate visibility
 	fmt.Printf("\n🚀 Server is running on http://%s\n", serverAddress)
@@ -75,8 +138,42 @@ func main() {
 	helpers.Success("🚀 Server is running on http://" + serverAddress)
 	helpers.Success("\n\t******************************************************************************************\n")
 
-	// Start server
-	if err := app.Listen(serverAddress); err != nil {
-		helpers.Error("❌ Failed to start server: %v", err)
+	// Keep serving while load balancers notice that /readyz fails, then stop
+	// accepting connections and wait for in-flight requests
+	if cfg.App.ShutdownDrainDelay > 0 {
+		helpers.OnShutdown(helpers.ShutdownPhaseHTTP, "readiness drain", func(ctx context.Context) error {
+			select {
+			case <-time.After(cfg.App.ShutdownDrainDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}
+	helpers.OnShutdown(helpers.ShutdownPhaseHTTP, "http server", app.ShutdownWithContext)
+
+	// Start server and wait for it to fail or for a shutdown signal
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.Listen(serverAddress)
+	}()
+
+	quit := make(chan os.Signal, 2)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			helpers.Error("❌ Failed to start server", err)
+		}
+	case sig := <-quit:
+		helpers.Info("Received %s, shutting down server...", sig)
+
+		// A second signal skips the graceful teardown
+		go func() {
+			<-quit
+			fmt.Println("Forced shutdown")
+			os.Exit(1)
+		}()
 	}
 }
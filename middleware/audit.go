@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"go-fiber-template/config"
+	"go-fiber-template/helpers"
+	"go-fiber-template/requests"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditConfig controls which requests AuditLogger persists and how much it captures
+type AuditConfig struct {
+	// IncludePaths limits auditing to matching paths; empty means every path
+	IncludePaths []string
+	// ExcludePaths are never audited, even if they match IncludePaths
+	ExcludePaths []string
+	// MaxBodySize is the maximum number of request/response body bytes stored
+	MaxBodySize int
+	// Redactor masks secrets in URLs, headers and bodies before they are logged
+	Redactor *helpers.Redactor
+}
+
+// AuditConfigFrom builds the audit configuration from the application settings
+func AuditConfigFrom(settings config.AuditConfig, redact config.RedactConfig) AuditConfig {
+	return AuditConfig{
+		IncludePaths: settings.IncludePaths,
+		ExcludePaths: settings.ExcludePaths,
+		MaxBodySize:  settings.MaxBodySize,
+		Redactor:     helpers.NewRedactorFromConfig(redact),
+	}
+}
+
+// ShouldAudit reports whether requests to the given path are audited.
+// Patterns ending in "*" match by prefix, others use path.Match.
+func (config AuditConfig) ShouldAudit(requestPath string) bool {
+	for _, pattern := range config.ExcludePaths {
+		if matchPath(pattern, requestPath) {
+			return false
+		}
+	}
+
+	if len(config.IncludePaths) == 0 {
+		return true
+	}
+
+	for _, pattern := range config.IncludePaths {
+		if matchPath(pattern, requestPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditLogger middleware captures full request/response records into the async logger
+func AuditLogger(logger *helpers.AsyncLogger, config AuditConfig) fiber.Handler {
+	if config.Redactor == nil {
+		config.Redactor = helpers.NewRedactor(nil, nil)
+	}
+	redactor := config.Redactor
+
+	return func(c *fiber.Ctx) error {
+		if !config.ShouldAudit(c.Path()) {
+			return c.Next()
+		}
+
+		// Capture request data before handlers can modify it
+		start := time.Now()
+		method := c.Method()
+		url := redactor.RedactURL(c.OriginalURL())
+		requestBody := auditBody(redactor, c.Body(), c.Get(fiber.HeaderContentType), config.MaxBodySize)
+		requestHeaders := encodeHeaders(redactor, func(visit func(key, value []byte)) {
+			c.Request().Header.VisitAll(visit)
+		})
+
+		// Run the error handler here so the final status and body are captured
+		resolveError(c, c.Next())
+
+		duration := time.Since(start)
+		responseBody := auditBody(redactor, c.Response().Body(), string(c.Response().Header.ContentType()), config.MaxBodySize)
+
+		var userID *uint
+		if claims := helpers.GetAuthUser(c); claims != nil {
+			userID = &claims.UserID
+		}
+
+		logger.Log(requests.LogEntry{
+			Method:         method,
+			URL:            url,
+			RequestBody:    requestBody,
+			ResponseBody:   responseBody,
+			RequestHeaders: requestHeaders,
+			ResponseHeaders: encodeHeaders(redactor, func(visit func(key, value []byte)) {
+				c.Response().Header.VisitAll(visit)
+			}),
+			StatusCode: c.Response().StatusCode(),
+			Route:      c.Route().Path,
+			UserID:     userID,
+			IP:         c.IP(),
+			Duration:   duration,
+			RequestID:  helpers.GetRequestID(c),
+			CreatedAt:  start,
+		})
+
+		return nil
+	}
+}
+
+// encodeHeaders serializes headers visited by the given function as a redacted JSON object
+func encodeHeaders(redactor *helpers.Redactor, visitAll func(visit func(key, value []byte))) string {
+	headers := make(map[string]string)
+	visitAll(func(key, value []byte) {
+		name := string(key)
+		if existing, ok := headers[name]; ok {
+			headers[name] = existing + ", " + string(value)
+			return
+		}
+		headers[name] = string(value)
+	})
+
+	encoded, err := json.Marshal(redactor.RedactHeaders(headers))
+	if err != nil {
+		return "{}"
+	}
+	return string(encoded)
+}
+
+// auditBody returns the redacted body to store. Bodies over maxSize are replaced by a
+// placeholder without being parsed, as redacting them would cost more than the request
+// and a truncated body could no longer be parsed to find its secrets.
+func auditBody(redactor *helpers.Redactor, body []byte, contentType string, maxSize int) string {
+	if len(body) > maxSize {
+		return fmt.Sprintf("[%d bytes omitted]", len(body))
+	}
+	// Masks can make the redacted body slightly longer than the original
+	return truncateBody(redactor.RedactBody(body, contentType), maxSize)
+}
+
+// truncateBody copies the body, cutting it to at most maxSize bytes
+func truncateBody(body []byte, maxSize int) string {
+	if len(body) <= maxSize {
+		return string(body)
+	}
+	return string(body[:maxSize]) + "...[truncated]"
+}
+
+// matchPath matches a request path against a prefix ("/api/*") or path.Match pattern
+func matchPath(pattern, requestPath string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(requestPath, strings.TrimSuffix(pattern, "*"))
+	}
+	matched, err := path.Match(pattern, requestPath)
+	return err == nil && matched
+}
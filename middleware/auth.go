@@ -1,41 +1,50 @@
 package middleware
 
 import (
-	"os"
+	"go-fiber-template/apperrors"
+	"go-fiber-template/helpers"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func Protected() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Missing authorization header",
-			})
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return apperrors.Unauthorized("Missing authorization header")
 		}
 
-		tokenString := authHeader[7:] // Remove "Bearer " prefix
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		tokenString := helpers.ExtractBearerToken(c)
+		if tokenString == "" {
+			return apperrors.Unauthorized("Authorization header must use the Bearer scheme")
+		}
 
-		if err != nil || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token",
-			})
+		claims, err := helpers.ParseJWTToken(tokenString)
+		if err != nil {
+			return apperrors.Unauthorized("Invalid token")
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to parse claims",
-			})
+		if revoked, err := isTokenRevoked(c, claims); err != nil {
+			return apperrors.Internal("Failed to verify token", err)
+		} else if revoked {
+			return apperrors.Unauthorized("Token has been revoked")
 		}
 
 		c.Locals("user", claims)
 		return c.Next()
 	}
 }
+
+// isTokenRevoked checks the token claims against the revocation store.
+// Tokens without a jti cannot be revoked and are therefore rejected.
+func isTokenRevoked(c *fiber.Ctx, claims *helpers.AuthClaims) (bool, error) {
+	store := helpers.GetRevocationStore()
+	if store == nil {
+		return false, nil
+	}
+
+	if claims.ID == "" || claims.IssuedAt == nil {
+		return true, nil
+	}
+
+	return store.IsRevoked(c.UserContext(), claims.ID, claims.UserID, claims.IssuedAtTime())
+}
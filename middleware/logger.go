@@ -14,22 +14,27 @@ func RequestLogger() fiber.Handler {
 		// Start timer
 		start := time.Now()
 
-		// Process request
-		err := c.Next()
+		// Process request, rendering errors so the logged status is final
+		resolveError(c, c.Next())
 
 		// Calculate duration
 		duration := time.Since(start)
 
 		// Log request details
-		helpers.LogRequest(
-			c.Method(),
-			c.Path(),
-			c.IP(),
-			c.Get("User-Agent"),
-			c.Response().StatusCode(),
-			duration,
-		)
-
-		return err
+		event := helpers.RequestLogEvent{
+			Method:    c.Method(),
+			Path:      c.Path(),
+			Status:    c.Response().StatusCode(),
+			Latency:   duration,
+			IP:        c.IP(),
+			UserAgent: c.Get("User-Agent"),
+			RequestID: helpers.GetRequestID(c),
+		}
+		if claims := helpers.GetAuthUser(c); claims != nil {
+			event.UserID = claims.UserID
+		}
+		helpers.LogRequest(event)
+
+		return nil
 	}
 }
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"go-fiber-template/helpers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unmatchedRoute labels requests that did not match any route, keeping label cardinality bounded
+const unmatchedRoute = "(unmatched)"
+
+// Metrics middleware records request counts and latencies labeled by route template
+func Metrics() fiber.Handler {
+	var (
+		once   sync.Once
+		routes map[string]bool
+	)
+
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		resolveError(c, c.Next())
+		status := c.Response().StatusCode()
+
+		// Routes are registered before the first request is served
+		once.Do(func() {
+			routes = make(map[string]bool)
+			for _, route := range c.App().GetRoutes(true) {
+				routes[route.Method+" "+route.Path] = true
+			}
+		})
+
+		// Unmatched requests end on a middleware, whose path is not a route template
+		routePath := c.Route().Path
+		if !routes[c.Route().Method+" "+routePath] {
+			routePath = unmatchedRoute
+		}
+
+		labels := []string{c.Method(), routePath, strconv.Itoa(status)}
+		helpers.HTTPRequestsTotal.Inc(labels...)
+		helpers.HTTPRequestDuration.ObserveDuration(time.Since(start), labels...)
+
+		return nil
+	}
+}
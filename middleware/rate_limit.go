@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"go-fiber-template/helpers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit rejects requests once the key returned by keyFunc exceeds the limiter's
+// limit. Store failures are logged and the request is let through, so an outage
+// of the store does not lock every client out.
+func RateLimit(limiter *helpers.RateLimiter, keyFunc func(c *fiber.Ctx) string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := limiter.Allow(c.UserContext(), keyFunc(c))
+		if err != nil {
+			helpers.WithRequest(c).Warning("Rate limiter %s unavailable: %v", limiter.Name(), err)
+			return c.Next()
+		}
+
+		helpers.SetRateLimitHeaders(c, result)
+		if !result.Allowed {
+			helpers.RateLimitedRequestsTotal.Inc(limiter.Name())
+			helpers.WithRequest(c).Warning("Rate limit %s exceeded by %s", limiter.Name(), c.IP())
+			return helpers.RateLimitError(result.RetryAfter)
+		}
+		return c.Next()
+	}
+}
+
+// ClientIP keys rate limits by the client IP address
+func ClientIP(c *fiber.Ctx) string {
+	return c.IP()
+}
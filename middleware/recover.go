@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"go-fiber-template/apperrors"
+	"go-fiber-template/helpers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Recover turns a panic in a later handler into a server error and logs it with its stack trace
+func Recover() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				panicErr := fmt.Errorf("panic: %v", r)
+				helpers.WithRequest(c).With("stack", string(debug.Stack())).Error("Recovered from panic", panicErr)
+				err = apperrors.Internal("An unexpected error occurred", panicErr)
+			}
+		}()
+		return c.Next()
+	}
+}
+
+// resolveError renders an error returned by later handlers through the app's
+// error handler, so the response status is final when the calling middleware inspects it
+func resolveError(c *fiber.Ctx, err error) {
+	if err == nil {
+		return
+	}
+	if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+		_ = c.SendStatus(fiber.StatusInternalServerError)
+	}
+}
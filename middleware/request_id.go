@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"go-fiber-template/helpers"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const maxRequestIDLength = 128
+
+// RequestID middleware accepts a valid incoming X-Request-ID or generates one,
+// stores it in Locals and the user context, and echoes it in the response
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(helpers.RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Locals(helpers.RequestIDLocalsKey, requestID)
+		c.SetUserContext(helpers.ContextWithRequestID(c.UserContext(), requestID))
+		c.Set(helpers.RequestIDHeader, requestID)
+
+		return c.Next()
+	}
+}
+
+// isValidRequestID accepts short IDs made of letters, digits and . _ : -
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == ':', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
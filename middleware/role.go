@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"go-fiber-template/apperrors"
+	"go-fiber-template/helpers"
+	"go-fiber-template/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole allows the request only if the user has one of the given roles.
+// It must be used after Protected.
+func RequireRole(roles ...models.UserType) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := helpers.GetAuthUser(c)
+		if claims == nil {
+			return apperrors.Unauthorized("Missing authentication")
+		}
+
+		if !claims.HasRole(roles...) {
+			return apperrors.Forbidden("Insufficient permissions")
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireMinRole allows the request only if the user's role is at least the
+// given role in the hierarchy. It must be used after Protected.
+func RequireMinRole(role models.UserType) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := helpers.GetAuthUser(c)
+		if claims == nil {
+			return apperrors.Unauthorized("Missing authentication")
+		}
+
+		if !claims.HasMinRole(role) {
+			return apperrors.Forbidden("Insufficient permissions")
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"go-fiber-template/database"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: go-fiber-template migrate <command>
+
+commands:
+  up               auto-migrate the models and apply every pending migration
+  down [steps]     revert the last applied migration, or the given number of them
+  to <version>     apply or revert migrations until version is the latest applied (0 reverts all)
+  status           list migrations and whether they are applied
+  sync [--allow-drop-columns]
+                   apply the column, index and foreign key changes detected between
+                   the models and the database; columns are only dropped with the flag
+  plan [--format text|json]
+                   show the SQL sync and up would run without changing anything;
+                   exits with status 2 when changes are pending`
+
+// errPendingChanges is returned by plan when the schema is not up to date
+var errPendingChanges = errors.New("schema changes are pending")
+
+// migrateExitCode returns the exit status for an error of the migrate command:
+// 2 when plan found pending changes and 1 for failures
+func migrateExitCode(err error) int {
+	if errors.Is(err, errPendingChanges) {
+		return 2
+	}
+	return 1
+}
+
+// runMigrate runs the migrate subcommand against the configured database
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	command, args := args[0], args[1:]
+	switch command {
+	case "up", "down", "to", "status", "sync", "plan":
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		return err
+	}
+	defer database.CloseDB()
+
+	if command == "up" && len(args) == 0 {
+		return database.Migrate(db)
+	}
+	if command == "sync" {
+		return syncSchema(db, args)
+	}
+	if command == "plan" {
+		return planSchema(db, args)
+	}
+
+	migrator, err := database.NewVersionedMigrator(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch {
+	case command == "down" && len(args) <= 1:
+		steps := 1
+		if len(args) == 1 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive number, got %q", args[0])
+			}
+		}
+		count, err := migrator.Down(ctx, steps)
+		fmt.Printf("Reverted %d migration(s)\n", count)
+		return err
+
+	case command == "to" && len(args) == 1:
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("version must be a migration version or 0, got %q", args[0])
+		}
+		count, err := migrator.To(ctx, version)
+		fmt.Printf("Ran %d migration(s)\n", count)
+		return err
+
+	case command == "status" && len(args) == 0:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, status.State, appliedAt)
+		}
+		return w.Flush()
+	}
+
+	return errors.New(migrateUsage)
+}
+
+// syncSchema applies the changes the dynamic migrator detects between the models and the database
+func syncSchema(db *gorm.DB, args []string) error {
+	allowDropColumns := len(args) == 1 && args[0] == "--allow-drop-columns"
+	if len(args) > 0 && !allowDropColumns {
+		return errors.New(migrateUsage)
+	}
+	migrator, err := database.NewDynamicMigrator(db)
+	if err != nil {
+		return err
+	}
+	migrator.AllowDropColumns(allowDropColumns)
+
+	operations, err := migrator.DetectChanges()
+	if err != nil {
+		return err
+	}
+	if len(operations) == 0 {
+		fmt.Println("Schema is up to date")
+		return nil
+	}
+
+	applied := 0
+	for _, operation := range operations {
+		if operation.Type == "drop_column" && !allowDropColumns {
+			continue
+		}
+		fmt.Println("-", operation.Description)
+		applied++
+	}
+	if err := migrator.ExecuteMigrations(operations); err != nil {
+		return err
+	}
+	fmt.Printf("Applied %d change(s)\n", applied)
+	return nil
+}
+
+// planSchema prints the pending schema changes and versioned migrations
+func planSchema(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("plan", flag.ContinueOnError)
+	format := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || (*format != "text" && *format != "json") {
+		return errors.New(migrateUsage)
+	}
+
+	plan, err := database.BuildMigrationPlan(context.Background(), db)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(plan); err != nil {
+			return err
+		}
+	} else {
+		printPlan(plan)
+	}
+
+	if plan.HasChanges() {
+		return errPendingChanges
+	}
+	return nil
+}
+
+// printPlan writes a human-readable plan to stdout
+func printPlan(plan *database.MigrationPlan) {
+	if !plan.HasChanges() {
+		fmt.Println("No changes. The schema is up to date.")
+		return
+	}
+
+	if len(plan.Operations) > 0 {
+		fmt.Printf("Schema changes (%d, %d destructive):\n", len(plan.Operations), plan.DestructiveCount())
+		for _, operation := range plan.Operations {
+			marker, note := "+", ""
+			if operation.Destructive {
+				marker, note = "!", " [destructive]"
+			}
+			if operation.RequiresOptIn {
+				note += " [needs sync --allow-drop-columns]"
+			}
+			fmt.Printf("\n  %s %s%s\n", marker, operation.Description, note)
+			fmt.Println(indent(operation.SQL, "      "))
+		}
+		fmt.Println()
+	}
+
+	if len(plan.Migrations) > 0 {
+		fmt.Printf("Pending migrations (%d):\n", len(plan.Migrations))
+		for _, migration := range plan.Migrations {
+			fmt.Printf("\n  + %d_%s\n", migration.Version, migration.Name)
+			if migration.SQL == "" {
+				fmt.Println("      (Go migration)")
+				continue
+			}
+			fmt.Println(indent(migration.SQL, "      "))
+		}
+		fmt.Println()
+	}
+}
+
+// indent prefixes every non-empty line of text
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
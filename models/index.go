@@ -0,0 +1,17 @@
+package models
+
+// Index declares an index in a model's Indexes method, for indexes that are awkward
+// to express in struct tags such as partial indexes over several columns
+type Index struct {
+	// Name defaults to idx_<table>_<columns>
+	Name    string
+	Columns []string
+	Unique  bool
+	// Where makes the index partial, e.g. "deleted_at IS NULL"
+	Where string
+}
+
+// Indexer is implemented by models that declare indexes besides their tag indexes
+type Indexer interface {
+	Indexes() []Index
+}
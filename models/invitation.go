@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Invitation represents a single-use invite that creates a user with a preset
+// role and department when accepted
+type Invitation struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Email       string     `gorm:"not null;index" json:"email"`
+	UserType    UserType   `gorm:"type:varchar(20);not null" json:"user_type"`
+	Department  string     `gorm:"type:varchar(100)" json:"department"`
+	TokenHash   string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	InvitedByID uint       `gorm:"not null;index" json:"invited_by_id"`
+	InvitedBy   User       `gorm:"foreignKey:InvitedByID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func init() {
+	Register(&Invitation{}, RegisterOptions{})
+}
+
+// IsPending reports whether the invitation can still be accepted
+func (i *Invitation) IsPending() bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && time.Now().Before(i.ExpiresAt)
+}
+
+// Indexes declares the partial index behind the lookup of pending invitations by email
+func (Invitation) Indexes() []Index {
+	return []Index{
+		{
+			Name:    "idx_invitations_pending_email",
+			Columns: []string{"email"},
+			Where:   "accepted_at IS NULL AND revoked_at IS NULL",
+		},
+	}
+}
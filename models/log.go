@@ -9,12 +9,21 @@ import (
 // Log represents a database log entry
 type Log struct {
 	gorm.Model
-	Method          string    `json:"method"`
+	Method          string    `gorm:"index" json:"method"`
 	URL             string    `json:"url"`
 	RequestBody     string    `json:"request_body"`
 	ResponseBody    string    `json:"response_body"`
 	RequestHeaders  string    `json:"request_headers"`
 	ResponseHeaders string    `json:"response_headers"`
-	StatusCode      int       `json:"status_code"`
-	CreatedAt       time.Time `json:"created_at"`
+	StatusCode      int       `gorm:"index" json:"status_code"`
+	Route           string    `gorm:"type:varchar(255);index" json:"route"`
+	UserID          *uint     `gorm:"index" json:"user_id"`
+	IP              string    `gorm:"type:varchar(64)" json:"ip"`
+	DurationMs      float64   `json:"duration_ms"`
+	RequestID       string    `gorm:"type:varchar(128);index" json:"request_id"`
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+}
+
+func init() {
+	Register(&Log{}, RegisterOptions{})
 }
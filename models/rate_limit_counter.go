@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RateLimitCounter counts the hits of a rate limit key within one window
+type RateLimitCounter struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	Key         string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_rate_limit_counters_key_window" json:"key"`
+	WindowStart time.Time `gorm:"not null;uniqueIndex:idx_rate_limit_counters_key_window" json:"window_start"`
+	Count       int64     `gorm:"not null;default:0" json:"count"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+func init() {
+	Register(&RateLimitCounter{}, RegisterOptions{})
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// RegisterOptions configures how a registered model is migrated
+type RegisterOptions struct {
+	// Name identifies the model in migration logs and defaults to its type name
+	Name string
+	// DependsOn lists models to migrate first besides the ones its foreign keys reference
+	DependsOn []interface{}
+}
+
+// RegisteredModel is a model that migrations create and keep up to date
+type RegisteredModel struct {
+	Name      string
+	Model     interface{}
+	DependsOn []interface{}
+}
+
+var registered []RegisteredModel
+
+// Register adds a model to the migrated models, typically from an init function next
+// to the model. Migrations order models by their foreign keys, so the registration
+// order only decides between independent models. It panics on a model that is not a
+// pointer to a struct or is registered twice, as that is a programming error.
+func Register(model interface{}, opts RegisterOptions) {
+	modelType := reflect.TypeOf(model)
+	if modelType == nil || modelType.Kind() != reflect.Ptr || modelType.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("model %T must be a pointer to a struct", model))
+	}
+	for _, existing := range registered {
+		if reflect.TypeOf(existing.Model) == modelType {
+			panic(fmt.Sprintf("model %s is already registered", modelType.Elem().Name()))
+		}
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = modelType.Elem().Name()
+	}
+	registered = append(registered, RegisteredModel{Name: name, Model: model, DependsOn: opts.DependsOn})
+}
+
+// Registered returns the registered models in registration order
+func Registered() []RegisteredModel {
+	return slices.Clone(registered)
+}
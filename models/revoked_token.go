@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// RevokedToken represents a revoked access token. Rows with IssuedBefore set
+// revoke every access token of the user issued up to that time.
+type RevokedToken struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	JTI          string     `gorm:"column:jti;type:varchar(64);uniqueIndex;not null" json:"jti"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	IssuedBefore *time.Time `json:"issued_before,omitempty"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func init() {
+	Register(&RevokedToken{}, RegisterOptions{})
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// SchemaMigration records a versioned migration that has been applied
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Checksum  string    `gorm:"type:varchar(64)" json:"checksum"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
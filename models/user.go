@@ -16,18 +16,69 @@ const (
 	Employee        UserType = "employee"
 )
 
+// roleLevels defines the role hierarchy; a higher level includes every lower one
+var roleLevels = map[UserType]int{
+	Employee:        1,
+	DepartmentAdmin: 2,
+	GarmentsAdmin:   3,
+	SystemAdmin:     4,
+}
+
+// UserTypes returns every defined user type from lowest to highest
+func UserTypes() []UserType {
+	return []UserType{Employee, DepartmentAdmin, GarmentsAdmin, SystemAdmin}
+}
+
+// Level returns the position of the role in the hierarchy, or 0 if unknown
+func (t UserType) Level() int {
+	return roleLevels[t]
+}
+
+// IsValid reports whether the role is one of the defined user types
+func (t UserType) IsValid() bool {
+	return t.Level() > 0
+}
+
+// AtLeast reports whether the role is the given role or above it in the hierarchy
+func (t UserType) AtLeast(role UserType) bool {
+	return t.IsValid() && t.Level() >= role.Level()
+}
+
+// Below returns every user type lower than the role in the hierarchy
+func (t UserType) Below() []UserType {
+	var roles []UserType
+	for _, role := range UserTypes() {
+		if role.Level() < t.Level() {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
 type User struct {
-	ID        uint           `gorm:"primarykey" json:"id"`
-	Email     string         `gorm:"uniqueIndex;not null" json:"email"`
-	Password  string         `gorm:"not null" json:"-"`
-	Phone	 string          `gorm:"uniqueIndex;not null" json:"phone"`
-	Name      string         `json:"name"`
-	UserType  UserType       `gorm:"type:varchar(20);not null;default:'employee'" json:"user_type"`
-	IsActive  bool           `gorm:"default:true" json:"is_active"`
-	Avatar    string         `gorm:"default:'default-avatar.png'" json:"avatar"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID                  uint           `gorm:"primarykey" json:"id"`
+	Email               string         `gorm:"uniqueIndex;not null" json:"email"`
+	Password            string         `gorm:"not null" json:"-"`
+	Phone               *string        `gorm:"uniqueIndex" json:"phone"`
+	Name                string         `json:"name"`
+	UserType            UserType       `gorm:"type:varchar(20);not null;default:'employee';index" json:"user_type"`
+	Department          string         `gorm:"type:varchar(100);index" json:"department"`
+	IsActive            bool           `gorm:"default:true;index" json:"is_active"`
+	FailedLoginAttempts int            `gorm:"not null;default:0" json:"-"`
+	LockedUntil         *time.Time     `json:"-"`
+	Avatar              string         `gorm:"default:'default-avatar.png'" json:"avatar"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+func init() {
+	Register(&User{}, RegisterOptions{})
+}
+
+// IsLocked reports whether failed logins have locked the account at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
 
 func (u *User) HashPassword() error {
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUserTypeAtLeast(t *testing.T) {
+	tests := []struct {
+		role UserType
+		min  UserType
+		want bool
+	}{
+		{SystemAdmin, SystemAdmin, true},
+		{SystemAdmin, Employee, true},
+		{GarmentsAdmin, DepartmentAdmin, true},
+		{DepartmentAdmin, DepartmentAdmin, true},
+		{DepartmentAdmin, GarmentsAdmin, false},
+		{Employee, DepartmentAdmin, false},
+		{Employee, Employee, true},
+		{UserType("unknown"), Employee, false},
+		{UserType(""), UserType("unknown"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" at least "+string(tt.min), func(t *testing.T) {
+			if got := tt.role.AtLeast(tt.min); got != tt.want {
+				t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.role, tt.min, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserTypeBelow(t *testing.T) {
+	tests := []struct {
+		role UserType
+		want []UserType
+	}{
+		{SystemAdmin, []UserType{Employee, DepartmentAdmin, GarmentsAdmin}},
+		{GarmentsAdmin, []UserType{Employee, DepartmentAdmin}},
+		{DepartmentAdmin, []UserType{Employee}},
+		{Employee, nil},
+		{UserType("unknown"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			if got := tt.role.Below(); !slices.Equal(got, tt.want) {
+				t.Errorf("%q.Below() = %v, want %v", tt.role, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package requests
 
 import (
-	"github.com/go-playground/validator/v10"
+	"go-fiber-template/apperrors"
+
+	ut "github.com/go-playground/universal-translator"
 )
 
-// LoginRequest represents the login request structure with validation rules
+// LoginRequest represents the login request structure with validation rules;
+// users log in with either their email or their phone number
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
+	Email    string `json:"email" validate:"omitempty,email"`
+	Phone    string `json:"phone" validate:"omitempty,phone"`
 	Password string `json:"password" validate:"required,min=6"`
 }
 
@@ -14,46 +18,25 @@ type LoginRequest struct {
 type RegisterRequest struct {
 	Name     string `json:"name" validate:"required,min=2"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
-	UserType string `json:"user_type,omitempty"`
+	Phone    string `json:"phone" validate:"omitempty,phone"`
+	Password string `json:"password" validate:"required,strong_password"`
 }
 
-// ValidateLoginRequest validates the login request
-func (r *LoginRequest) Validate() []string {
-	validate := validator.New()
-	var errors []string
-
-	err := validate.Struct(r)
-	if err != nil {
-		for _, err := range err.(validator.ValidationErrors) {
-			switch err.Field() {
-			case "Email":
-				errors = append(errors, "Email is required and must be a valid email address")
-			case "Password":
-				errors = append(errors, "Password must be at least 6 characters long")
-			}
-		}
-	}
-	return errors
+// RefreshRequest represents the refresh token request structure with validation rules
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
-// ValidateRegisterRequest validates the registration request
-func (r *RegisterRequest) Validate() []string {
-	validate := validator.New()
-	var errors []string
+// LogoutRequest represents the logout request structure; the refresh token is optional
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
 
-	err := validate.Struct(r)
-	if err != nil {
-		for _, err := range err.(validator.ValidationErrors) {
-			switch err.Field() {
-			case "Name":
-				errors = append(errors, "Name must be at least 2 characters long")
-			case "Email":
-				errors = append(errors, "Email is required and must be a valid email address")
-			case "Password":
-				errors = append(errors, "Password must be at least 6 characters long")
-			}
-		}
+// validateCrossFields requires an email or a phone number
+func (r *LoginRequest) validateCrossFields(trans ut.Translator) []apperrors.FieldError {
+	if r.Email == "" && r.Phone == "" {
+		message, _ := trans.T("email_or_phone")
+		return []apperrors.FieldError{{Field: "email", Rule: "email_or_phone", Message: message}}
 	}
-	return errors
+	return nil
 }
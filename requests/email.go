@@ -0,0 +1,9 @@
+package requests
+
+import "strings"
+
+// NormalizeEmail returns the form emails are stored and looked up in: trimmed and
+// lowercased, so every spelling of an address belongs to one account
+func NormalizeEmail(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
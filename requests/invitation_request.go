@@ -0,0 +1,16 @@
+package requests
+
+// CreateInvitationRequest represents the invitation request structure with validation rules
+type CreateInvitationRequest struct {
+	Email      string `json:"email" validate:"required,email"`
+	UserType   string `json:"user_type" validate:"required,user_type"`
+	Department string `json:"department" validate:"omitempty,max=100"`
+}
+
+// AcceptInvitationRequest represents the invitation acceptance structure with validation rules
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"required,min=2"`
+	Phone    string `json:"phone" validate:"omitempty,phone"`
+	Password string `json:"password" validate:"required,strong_password"`
+}
@@ -1,6 +1,12 @@
 package requests
 
-import "time"
+import (
+	"time"
+
+	"go-fiber-template/apperrors"
+
+	ut "github.com/go-playground/universal-translator"
+)
 
 // LogEntry represents a log entry in the system
 type LogEntry struct {
@@ -11,5 +17,45 @@ type LogEntry struct {
 	RequestHeaders  string
 	ResponseHeaders string
 	StatusCode      int
+	Route           string
+	UserID          *uint
+	IP              string
+	Duration        time.Duration
+	RequestID       string
 	CreatedAt       time.Time
 }
+
+// LogQuery represents the filters accepted by the log query and stats endpoints
+type LogQuery struct {
+	Method    string `query:"method" validate:"omitempty,oneof=GET POST PUT PATCH DELETE HEAD OPTIONS"`
+	StatusMin int    `query:"status_min" validate:"omitempty,min=100,max=599"`
+	StatusMax int    `query:"status_max" validate:"omitempty,min=100,max=599"`
+	URLPrefix string `query:"url_prefix" validate:"omitempty,max=255"`
+	From      string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To        string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	UserID    uint   `query:"user_id"`
+	RequestID string `query:"request_id" validate:"omitempty,max=128"`
+	Cursor    string `query:"cursor" validate:"omitempty,max=64"`
+	Limit     int    `query:"limit" validate:"omitempty,min=1,max=200"`
+}
+
+// FromTime returns the parsed start of the time window, or the zero time
+func (r *LogQuery) FromTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, r.From)
+	return t
+}
+
+// ToTime returns the parsed end of the time window, or the zero time
+func (r *LogQuery) ToTime() time.Time {
+	t, _ := time.Parse(time.RFC3339, r.To)
+	return t
+}
+
+// validateCrossFields checks that the status range is not inverted
+func (r *LogQuery) validateCrossFields(trans ut.Translator) []apperrors.FieldError {
+	if r.StatusMin > 0 && r.StatusMax > 0 && r.StatusMin > r.StatusMax {
+		message, _ := trans.T("status_range", "status_max", "status_min")
+		return []apperrors.FieldError{{Field: "status_max", Rule: "status_range", Message: message}}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package requests
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"go-fiber-template/config"
+)
+
+// e164Pattern matches an E.164 number: + followed by up to 15 digits without a leading zero
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// phoneSeparators are the characters people commonly type between phone digits
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+
+var errInvalidPhone = errors.New("invalid phone number")
+
+// NormalizePhone converts a phone number to E.164, e.g. "+1 (415) 555-2671" to
+// "+14155552671". An international 00 prefix is accepted in place of +. Numbers
+// without a country code get PHONE_DEFAULT_COUNTRY_CODE after dropping the
+// trunk prefix 0, and are rejected when no default is configured.
+func NormalizePhone(raw string) (string, error) {
+	phone := phoneSeparators.Replace(strings.TrimSpace(raw))
+	if rest, ok := strings.CutPrefix(phone, "00"); ok {
+		phone = "+" + rest
+	}
+
+	if !strings.HasPrefix(phone, "+") {
+		countryCode := config.Get().App.PhoneDefaultCountryCode
+		if countryCode == "" {
+			return "", errInvalidPhone
+		}
+		phone = "+" + countryCode + strings.TrimPrefix(phone, "0")
+	}
+
+	if !e164Pattern.MatchString(phone) {
+		return "", errInvalidPhone
+	}
+	return phone, nil
+}
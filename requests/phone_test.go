@@ -0,0 +1,49 @@
+package requests
+
+import (
+	"testing"
+
+	"go-fiber-template/config"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		countryCode string
+		want        string
+		wantErr     bool
+	}{
+		{name: "E.164", raw: "+14155552671", want: "+14155552671"},
+		{name: "separators", raw: " +1 (415) 555-2671 ", want: "+14155552671"},
+		{name: "dots", raw: "+44.20.7946.0958", want: "+442079460958"},
+		{name: "international 00 prefix", raw: "0044 20 7946 0958", want: "+442079460958"},
+		{name: "national number with default country code", raw: "020 7946 0958", countryCode: "44", want: "+442079460958"},
+		{name: "national number without trunk prefix", raw: "4155552671", countryCode: "1", want: "+14155552671"},
+		{name: "national number without default country code", raw: "020 7946 0958", wantErr: true},
+		{name: "leading zero country code", raw: "+0123456789", wantErr: true},
+		{name: "too short", raw: "+12345", wantErr: true},
+		{name: "too long", raw: "+1234567890123456", wantErr: true},
+		{name: "letters", raw: "+1 415 CALL NOW", wantErr: true},
+		{name: "empty", raw: "", countryCode: "1", wantErr: true},
+	}
+
+	previous := config.Get()
+	t.Cleanup(func() { config.Set(previous) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.App.PhoneDefaultCountryCode = tt.countryCode
+			config.Set(cfg)
+
+			got, err := NormalizePhone(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizePhone(%q) error = %v, want error %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePhone(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package requests
+
+// UserListQuery represents the user list query parameters with validation rules
+type UserListQuery struct {
+	Page     int    `query:"page" validate:"omitempty,min=1"`
+	PerPage  int    `query:"per_page" validate:"omitempty,min=1,max=100"`
+	UserType string `query:"user_type" validate:"omitempty,user_type"`
+	IsActive string `query:"is_active" validate:"omitempty,oneof=true false"`
+	Search   string `query:"search" validate:"omitempty,max=100"`
+	Trashed  string `query:"trashed" validate:"omitempty,oneof=with only"`
+}
+
+// UpdateUserRequest represents the user update structure; omitted fields are left unchanged
+type UpdateUserRequest struct {
+	Name       *string `json:"name" validate:"omitempty,min=2"`
+	Phone      *string `json:"phone" validate:"omitempty,phone"`
+	UserType   *string `json:"user_type" validate:"omitempty,user_type"`
+	Department *string `json:"department" validate:"omitempty,max=100"`
+	IsActive   *bool   `json:"is_active"`
+	Avatar     *string `json:"avatar" validate:"omitempty,max=255"`
+}
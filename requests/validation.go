@@ -0,0 +1,259 @@
+package requests
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go-fiber-template/apperrors"
+	"go-fiber-template/models"
+
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	de_translations "github.com/go-playground/validator/v10/translations/de"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+)
+
+// DefaultLocale is used when the client accepts none of the supported locales
+const DefaultLocale = "en"
+
+const minStrongPasswordLength = 8
+
+// customMessages translates the custom rules, the cross-field checks and the
+// fallback for rules without a translation. {0} is the field and {1} the rule parameter.
+var customMessages = map[string]map[string]string{
+	"en": {
+		"phone":           "{0} must be a valid phone number including the country code, e.g. +14155552671",
+		"email_or_phone":  "Either email or phone is required",
+		"strong_password": "{0} must be at least {1} characters long and contain an upper case letter, a lower case letter and a digit",
+		"user_type":       "{0} must be one of {1}",
+		"status_range":    "{0} must not be less than {1}",
+		"invalid":         "{0} is invalid",
+	},
+	"es": {
+		"phone":           "{0} debe ser un número de teléfono válido con el prefijo del país, p. ej. +14155552671",
+		"email_or_phone":  "Se requiere el correo electrónico o el teléfono",
+		"strong_password": "{0} debe tener al menos {1} caracteres e incluir una letra mayúscula, una letra minúscula y un dígito",
+		"user_type":       "{0} debe ser uno de {1}",
+		"status_range":    "{0} no debe ser menor que {1}",
+		"invalid":         "{0} no es válido",
+	},
+	"fr": {
+		"phone":           "{0} doit être un numéro de téléphone valide avec l'indicatif du pays, par ex. +14155552671",
+		"email_or_phone":  "L'e-mail ou le téléphone est requis",
+		"strong_password": "{0} doit contenir au moins {1} caractères dont une majuscule, une minuscule et un chiffre",
+		"user_type":       "{0} doit être l'une des valeurs suivantes : {1}",
+		"status_range":    "{0} ne doit pas être inférieur à {1}",
+		"invalid":         "{0} n'est pas valide",
+	},
+	"de": {
+		"phone":           "{0} muss eine gültige Telefonnummer mit Ländervorwahl sein, z. B. +14155552671",
+		"email_or_phone":  "E-Mail oder Telefon ist erforderlich",
+		"strong_password": "{0} muss mindestens {1} Zeichen lang sein und einen Großbuchstaben, einen Kleinbuchstaben und eine Ziffer enthalten",
+		"user_type":       "{0} muss einer der folgenden Werte sein: {1}",
+		"status_range":    "{0} darf nicht kleiner als {1} sein",
+		"invalid":         "{0} ist ungültig",
+	},
+}
+
+// customRules are the validation rules added to the built-in ones
+var customRules = map[string]validator.Func{
+	"phone":           isPhoneNumber,
+	"strong_password": isStrongPassword,
+	"user_type":       isUserType,
+}
+
+// crossFieldValidator is implemented by requests with rules spanning several fields
+type crossFieldValidator interface {
+	validateCrossFields(trans ut.Translator) []apperrors.FieldError
+}
+
+var validate, translators = newValidator()
+
+// newValidator builds the shared validator with every supported locale. It panics
+// on registration errors as those are programming errors.
+func newValidator() (*validator.Validate, *ut.UniversalTranslator) {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(fieldName)
+
+	for tag, fn := range customRules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			panic(err)
+		}
+	}
+
+	uni := ut.New(en.New(), en.New(), es.New(), fr.New(), de.New())
+	defaults := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"es": es_translations.RegisterDefaultTranslations,
+		"fr": fr_translations.RegisterDefaultTranslations,
+		"de": de_translations.RegisterDefaultTranslations,
+	}
+	for locale, register := range defaults {
+		trans, _ := uni.GetTranslator(locale)
+		if err := register(v, trans); err != nil {
+			panic(err)
+		}
+		for key, message := range customMessages[locale] {
+			if err := trans.Add(key, message, true); err != nil {
+				panic(err)
+			}
+		}
+		for tag := range customRules {
+			err := v.RegisterTranslation(tag, trans, func(ut.Translator) error { return nil }, translateCustomRule)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	return v, uni
+}
+
+// Validate checks input against its validate tags and cross-field rules and
+// returns every problem with a message in the given locale
+func Validate(input interface{}, locale string) []apperrors.FieldError {
+	trans := translator(locale)
+
+	var fields []apperrors.FieldError
+	if err := validate.Struct(input); err != nil {
+		validationErrors, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return []apperrors.FieldError{{Rule: "invalid", Message: err.Error()}}
+		}
+		for _, fieldErr := range validationErrors {
+			fields = append(fields, apperrors.FieldError{
+				Field:   fieldErr.Field(),
+				Rule:    fieldErr.Tag(),
+				Message: translateFieldError(fieldErr, trans),
+			})
+		}
+	}
+
+	if checker, ok := input.(crossFieldValidator); ok {
+		fields = append(fields, checker.validateCrossFields(trans)...)
+	}
+	return fields
+}
+
+// MatchLocale returns the supported locale the client prefers according to an
+// Accept-Language header, or DefaultLocale
+func MatchLocale(acceptLanguage string) string {
+	type preference struct {
+		tag     string
+		quality float64
+	}
+
+	var preferences []preference
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = q
+			}
+		}
+		if tag != "" && quality > 0 {
+			preferences = append(preferences, preference{tag: strings.ToLower(tag), quality: quality})
+		}
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	for _, pref := range preferences {
+		base, _, _ := strings.Cut(strings.ReplaceAll(pref.tag, "_", "-"), "-")
+		if _, ok := customMessages[base]; ok {
+			return base
+		}
+	}
+	return DefaultLocale
+}
+
+// translator returns the translator of a supported locale, or of DefaultLocale
+func translator(locale string) ut.Translator {
+	if trans, found := translators.GetTranslator(locale); found {
+		return trans
+	}
+	trans, _ := translators.GetTranslator(DefaultLocale)
+	return trans
+}
+
+// translateFieldError translates a field error, falling back to English and
+// then to a generic message for rules without a translation
+func translateFieldError(fieldErr validator.FieldError, trans ut.Translator) string {
+	if message := fieldErr.Translate(trans); message != fieldErr.Error() {
+		return message
+	}
+	if message := fieldErr.Translate(translator(DefaultLocale)); message != fieldErr.Error() {
+		return message
+	}
+	message, _ := trans.T("invalid", fieldErr.Field())
+	return message
+}
+
+// translateCustomRule translates a failed custom rule
+func translateCustomRule(trans ut.Translator, fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "strong_password":
+		param = strconv.Itoa(minStrongPasswordLength)
+	case "user_type":
+		names := make([]string, 0, len(models.UserTypes()))
+		for _, userType := range models.UserTypes() {
+			names = append(names, string(userType))
+		}
+		param = strings.Join(names, ", ")
+	}
+	message, _ := trans.T(fieldErr.Tag(), fieldErr.Field(), param)
+	return message
+}
+
+// fieldName reports fields by their json or query name, as clients send them
+func fieldName(field reflect.StructField) string {
+	for _, tagName := range []string{"json", "query"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tagName), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// isPhoneNumber accepts numbers that NormalizePhone can convert to E.164
+func isPhoneNumber(fl validator.FieldLevel) bool {
+	_, err := NormalizePhone(fl.Field().String())
+	return err == nil
+}
+
+// isStrongPassword requires a minimum length, an upper and a lower case letter and a digit
+func isStrongPassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+	var upper, lower, digit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return len([]rune(password)) >= minStrongPasswordLength && upper && lower && digit
+}
+
+// isUserType accepts the defined user types
+func isUserType(fl validator.FieldLevel) bool {
+	return models.UserType(fl.Field().String()).IsValid()
+}
@@ -0,0 +1,106 @@
+package requests
+
+import (
+	"reflect"
+	"testing"
+
+	"go-fiber-template/apperrors"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  interface{}
+		locale string
+		want   []apperrors.FieldError
+	}{
+		{
+			name:   "valid input",
+			input:  &RegisterRequest{Name: "Jane", Email: "jane@example.com", Password: "Secret123"},
+			locale: "en",
+		},
+		{
+			name:   "built-in rules use json names",
+			input:  &RegisterRequest{Name: "J", Email: "jane", Password: "Secret123"},
+			locale: "en",
+			want: []apperrors.FieldError{
+				{Field: "name", Rule: "min", Message: "name must be at least 2 characters in length"},
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+			},
+		},
+		{
+			name:   "built-in rule in Spanish",
+			input:  &RefreshRequest{},
+			locale: "es",
+			want: []apperrors.FieldError{
+				{Field: "refresh_token", Rule: "required", Message: "refresh_token es un campo requerido"},
+			},
+		},
+		{
+			name:   "custom rules",
+			input:  &RegisterRequest{Name: "Jane", Email: "jane@example.com", Phone: "12", Password: "secret"},
+			locale: "en",
+			want: []apperrors.FieldError{
+				{Field: "phone", Rule: "phone", Message: "phone must be a valid phone number including the country code, e.g. +14155552671"},
+				{Field: "password", Rule: "strong_password", Message: "password must be at least 8 characters long and contain an upper case letter, a lower case letter and a digit"},
+			},
+		},
+		{
+			name:   "custom rule in German",
+			input:  &RegisterRequest{Name: "Jane", Email: "jane@example.com", Password: "secret"},
+			locale: "de",
+			want: []apperrors.FieldError{
+				{Field: "password", Rule: "strong_password", Message: "password muss mindestens 8 Zeichen lang sein und einen Großbuchstaben, einen Kleinbuchstaben und eine Ziffer enthalten"},
+			},
+		},
+		{
+			name:   "cross-field rule in French",
+			input:  &LoginRequest{Password: "secret"},
+			locale: "fr",
+			want: []apperrors.FieldError{
+				{Field: "email", Rule: "email_or_phone", Message: "L'e-mail ou le téléphone est requis"},
+			},
+		},
+		{
+			name:   "unsupported locale falls back to English",
+			input:  &RefreshRequest{},
+			locale: "ja",
+			want: []apperrors.FieldError{
+				{Field: "refresh_token", Rule: "required", Message: "refresh_token is a required field"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Validate(tt.input, tt.locale); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "en"},
+		{"fr", "fr"},
+		{"de-DE,de;q=0.9,en;q=0.8", "de"},
+		{"ja,es;q=0.5", "es"},
+		{"en;q=0.2, fr;q=0.8", "fr"},
+		{"es_MX", "es"},
+		{"FR-ca", "fr"},
+		{"fr;q=0, de", "de"},
+		{"ja, zh", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			if got := MatchLocale(tt.acceptLanguage); got != tt.want {
+				t.Errorf("MatchLocale(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
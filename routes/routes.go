@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"go-fiber-template/config"
 	"go-fiber-template/controllers"
+	"go-fiber-template/helpers"
 	"go-fiber-template/middleware"
+	"go-fiber-template/models"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -11,20 +14,78 @@ import (
 func SetupRoutes(app *fiber.App, db *gorm.DB) {
 	// Auth routes
 	authController := controllers.NewAuthController(db)
+	invitationController := controllers.NewInvitationController(db)
+	userController := controllers.NewUserController(db)
+	logController := controllers.NewLogController(db)
+	healthController := controllers.NewHealthController()
+	metricsController := controllers.NewMetricsController()
+
+	// Liveness and readiness probes
+	app.Get("/healthz", healthController.Healthz)
+	app.Get("/readyz", healthController.Readyz)
+
+	// Prometheus metrics
+	app.Get("/metrics", metricsController.Show)
 
 	auth := app.Group("/api/auth")
+	authIPLimit, loginIPLimit := authRateLimits()
+	if authIPLimit != nil {
+		auth.Use(authIPLimit)
+	}
 	auth.Post("/register", authController.Register)
-	auth.Post("/login", authController.Login)
+	if loginIPLimit != nil {
+		auth.Post("/login", loginIPLimit, authController.Login)
+	} else {
+		auth.Post("/login", authController.Login)
+	}
+	auth.Post("/refresh", authController.Refresh)
+	auth.Post("/accept-invite", invitationController.Accept)
 
 	// Protected routes example
 	api := app.Group("/api")
 	api.Use(middleware.Protected())
 
+	api.Post("/logout", authController.Logout)
+	api.Post("/logout-all", authController.LogoutAll)
+
+	// Invitation routes
+	invitations := api.Group("/invitations", middleware.RequireMinRole(models.DepartmentAdmin))
+	invitations.Get("/", invitationController.List)
+	invitations.Post("/", invitationController.Create)
+	invitations.Delete("/:id", invitationController.Revoke)
+
+	// Current user routes
+	api.Get("/profile", userController.Profile)
+	api.Get("/user", userController.Profile)
+
+	// User management routes
+	users := api.Group("/users", middleware.RequireMinRole(models.DepartmentAdmin))
+	users.Get("/", userController.List)
+	users.Get("/:id", userController.Show)
+	users.Put("/:id", userController.Update)
+	users.Post("/:id/deactivate", userController.Deactivate)
+	users.Delete("/:id", userController.Delete)
+	users.Post("/:id/restore", userController.Restore)
+	users.Post("/:id/unlock", userController.Unlock)
+
+	// System admin routes
+	admin := api.Group("/admin", middleware.RequireRole(models.SystemAdmin))
+	admin.Get("/logs", logController.Index)
+	admin.Get("/logs/stats", logController.Stats)
+
 	// Add protected routes here
-	api.Get("/profile", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message": "Protected route",
-			"user":    c.Locals("user"),
-		})
-	})
+}
+
+// authRateLimits returns the per-IP limits of every auth endpoint and of login
+// attempts, or nil handlers when rate limiting is disabled
+func authRateLimits() (fiber.Handler, fiber.Handler) {
+	settings := config.Get().RateLimit
+	store := helpers.GetRateLimitStore()
+	if !settings.Enabled || store == nil {
+		return nil, nil
+	}
+
+	authIP := helpers.NewRateLimiter(store, "auth_ip", settings.AuthIPLimit, settings.AuthIPWindow, settings.Algorithm)
+	loginIP := helpers.NewRateLimiter(store, "login_ip", settings.LoginIPLimit, settings.LoginIPWindow, settings.Algorithm)
+	return middleware.RateLimit(authIP, middleware.ClientIP), middleware.RateLimit(loginIP, middleware.ClientIP)
 }
@@ -0,0 +1,215 @@
+// Package testutil provides a scripted PostgreSQL stand-in for tests that run
+// without a database. Statements reach a handler that decides their result, and
+// every statement is recorded so tests can check what was sent.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Query is a statement received by the scripted database
+type Query struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// Result is the answer of the handler to a statement. Columns and Rows are
+// returned to queries, RowsAffected to statements run without rows.
+type Result struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+	Err          error
+}
+
+// Handler returns the result of a statement
+type Handler func(query Query) Result
+
+// DB is a GORM connection backed by a handler
+type DB struct {
+	*gorm.DB
+
+	handler Handler
+	mu      sync.Mutex
+	queries []Query
+}
+
+// NewDB opens a GORM connection with the postgres dialect whose statements are
+// answered by handler. A nil handler answers every statement with no rows.
+func NewDB(t testing.TB, handler Handler) *DB {
+	t.Helper()
+	if handler == nil {
+		handler = func(Query) Result { return Result{} }
+	}
+
+	db := &DB{handler: handler}
+	sqlDB := sql.OpenDB(connector{db: db})
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("failed to open scripted database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db.DB = gormDB
+	return db
+}
+
+// Queries returns the statements received so far
+func (db *DB) Queries() []Query {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]Query(nil), db.queries...)
+}
+
+// Matching returns the received statements containing fragment
+func (db *DB) Matching(fragment string) []Query {
+	var matched []Query
+	for _, query := range db.Queries() {
+		if strings.Contains(query.SQL, fragment) {
+			matched = append(matched, query)
+		}
+	}
+	return matched
+}
+
+// run records the statement and asks the handler for its result
+func (db *DB) run(query string, args []driver.NamedValue) Result {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	recorded := Query{SQL: query, Args: values}
+
+	db.mu.Lock()
+	db.queries = append(db.queries, recorded)
+	db.mu.Unlock()
+	return db.handler(recorded)
+}
+
+type connector struct {
+	db *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return scriptedDriver{}
+}
+
+type scriptedDriver struct{}
+
+func (scriptedDriver) Open(string) (driver.Conn, error) {
+	return nil, driver.ErrSkip
+}
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return tx{}, nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result := c.db.run(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return driver.RowsAffected(result.RowsAffected), nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.db.run(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return &rows{columns: result.Columns, values: result.Rows}, nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+type tx struct{}
+
+func (tx) Commit() error {
+	return nil
+}
+
+func (tx) Rollback() error {
+	return nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+// namedValues converts positional arguments to named ones
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}